@@ -0,0 +1,37 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/chezu/video-journal/internal/drafts"
+)
+
+// runApprove implements the approve subcommand, which moves a staged draft
+// to its final output location
+func runApprove(args []string) error {
+	fs := flag.NewFlagSet("approve", flag.ExitOnError)
+	dirFlag := fs.String("drafts-dir", drafts.DefaultDir, "Directory holding staged drafts")
+	forceFlag := fs.Bool("force", false, "Overwrite the output file if it exists")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: video-journal approve [flags] <name>\n\n")
+		fmt.Fprintf(os.Stderr, "Move a staged draft to its final output location.\n\n")
+		fmt.Fprintf(os.Stderr, "Flags:\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	entry, err := drafts.Approve(*dirFlag, fs.Arg(0), *forceFlag)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Approved %s -> %s\n", entry.Name, entry.Output)
+	return nil
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/chezu/video-journal/internal/blog"
+	"github.com/chezu/video-journal/internal/cache"
+	"github.com/chezu/video-journal/internal/pipeline"
+)
+
+// errUnchanged marks an item skipped by --only-changed
+var errUnchanged = errors.New("unchanged since last run")
+
+// errBudgetExhausted marks an item skipped because the budget can't cover
+// another LLM call
+var errBudgetExhausted = errors.New("budget exhausted")
+
+// budget caps the total Claude CLI spend across a run
+type budget struct {
+	limit float64 // USD; 0 means unlimited
+	usage *blog.Usage
+}
+
+// allowsCall reports whether another LLM call fits in the budget. The next
+// call is assumed to cost as much as the average call so far.
+func (b *budget) allowsCall() bool {
+	if b == nil || b.limit <= 0 {
+		return true
+	}
+	spent, calls := b.usage.Cost()
+	estimate := 0.0
+	if calls > 0 {
+		estimate = spent / float64(calls)
+	}
+	return spent+estimate <= b.limit
+}
+
+// collectInputs expands directory arguments into the supported video files
+// they contain (non-recursive). Other arguments are returned as given.
+func collectInputs(args []string) ([]string, error) {
+	var inputs []string
+	for _, arg := range args {
+		info, err := os.Stat(arg)
+		if err != nil || !info.IsDir() {
+			inputs = append(inputs, arg)
+			continue
+		}
+
+		entries, err := os.ReadDir(arg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read directory: %w", err)
+		}
+		found := 0
+		for _, entry := range entries {
+			ext := strings.ToLower(filepath.Ext(entry.Name()))
+			if entry.IsDir() || !validVideoExtensions[ext] {
+				continue
+			}
+			inputs = append(inputs, filepath.Join(arg, entry.Name()))
+			found++
+		}
+		if found == 0 {
+			return nil, fmt.Errorf("no supported video files in directory: %s", arg)
+		}
+	}
+	return inputs, nil
+}
+
+// saveTranscript writes a transcript next to the intended output so it is not
+// lost when the post can't be generated
+func saveTranscript(cfg config, transcript string) error {
+	transcriptPath := strings.TrimSuffix(cfg.outputPath, filepath.Ext(cfg.outputPath)) + ".transcript.txt"
+	if cfg.bundleDir != "" {
+		transcriptPath = filepath.Join(cfg.bundleDir, bundleTranscript)
+	}
+	if err := writeOutputFile(transcriptPath, []byte(transcript+"\n"), cfg); err != nil {
+		return fmt.Errorf("failed to save transcript: %w", err)
+	}
+	fmt.Printf("Transcript saved to: %s\n", transcriptPath)
+	return nil
+}
+
+// styleHash fingerprints the style guide content; a missing file (the
+// built-in default) hashes as empty
+func styleHash(stylePath string) string {
+	data, _ := os.ReadFile(stylePath)
+	return cache.HashText(string(data))
+}
+
+// checkChanged decides whether a video's post needs regenerating by comparing
+// its transcript cache key and style guide with the last recorded run. It
+// returns the reason for regenerating, or errUnchanged.
+func checkChanged(videoPath string, cfg config) (string, error) {
+	record, ok, err := cfg.cache.LookupOutput(cfg.outputPath)
+	if err != nil {
+		return "", err
+	}
+	_, statErr := os.Stat(cfg.outputPath)
+	if !ok {
+		if statErr == nil && !cfg.force {
+			return "", fmt.Errorf("output file already exists but has no recorded run: %s\nUse --force to overwrite", cfg.outputPath)
+		}
+		return "no previous run recorded", nil
+	}
+	if statErr != nil {
+		return "output file missing", nil
+	}
+
+	key, err := cache.Key(videoPath, cfg.Transcribe.Fingerprint())
+	if err != nil {
+		return "", err
+	}
+	if key != record.Key {
+		return "video or transcription settings changed", nil
+	}
+	if styleHash(cfg.Blog.StylePath) != record.StyleHash {
+		return "style guide changed", nil
+	}
+	return "", errUnchanged
+}
+
+// runItem processes a single input according to its config
+func runItem(ctx context.Context, inputPath string, cfg config) error {
+	if cfg.proofreadOnly {
+		return runProofread(ctx, inputPath, cfg)
+	}
+
+	// The track is part of the transcription fingerprint, so pick it before
+	// comparing against the recorded run
+	if cfg.selectAudio {
+		track, err := selectAudioTrack(ctx, inputPath)
+		if err != nil {
+			return err
+		}
+		cfg.Transcribe.AudioTrack = track
+	}
+
+	if cfg.onlyChanged && !cfg.json && !cfg.lintStyle {
+		reason, err := checkChanged(inputPath, cfg)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Regenerating: %s\n", reason)
+	}
+
+	if cfg.json {
+		return runTranscriptJSON(ctx, inputPath, cfg)
+	}
+	if cfg.lintStyle {
+		return runLintStyle(ctx, inputPath, cfg)
+	}
+	return run(ctx, inputPath, cfg)
+}
+
+// runBatch processes the inputs with up to workers running at once. Each
+// worker holds at most one ffmpeg or whisper slot at a time, so more workers
+// than slots keep the stages overlapped without exceeding their limits.
+// Inputs are skipped once the budget can't cover another LLM call; items
+// already running may still overshoot it slightly. Failures are collected
+// and summarized at the end unless failFast stops the batch at the first.
+// Returns an error if any input failed.
+func runBatch(ctx context.Context, inputs []string, prepare func(string) (config, error), b *budget, workers int, failFast bool) error {
+	result := pipeline.RunBatch(ctx, inputs, pipeline.BatchOptions{
+		Workers:  workers,
+		FailFast: failFast,
+		IsSkip: func(err error) bool {
+			return errors.Is(err, errUnchanged) || errors.Is(err, errBudgetExhausted)
+		},
+	}, func(ctx context.Context, i int, input string) error {
+		fmt.Printf("\n=== [%d/%d] %s ===\n", i+1, len(inputs), input)
+		cfg, err := prepare(input)
+		if cfg.titleNumbers != nil {
+			defer cfg.titleNumbers.finish(i)
+			cfg.titleSeq = i
+		}
+		if err != nil {
+			return err
+		}
+		if !cfg.json && !b.allowsCall() {
+			return errBudgetExhausted
+		}
+		return runItem(ctx, input, cfg)
+	})
+
+	printBatchSummary(result)
+	if b.limit > 0 {
+		spent, _ := b.usage.Cost()
+		fmt.Printf("Spent $%.2f of $%.2f budget\n", spent, b.limit)
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return result.Err()
+}
+
+// printBatchSummary reports the batch totals and the outcome of every item
+// that wasn't processed
+func printBatchSummary(result pipeline.BatchResult) {
+	fmt.Printf("\nBatch complete: %d processed, %d skipped, %d failed", result.Count(pipeline.ItemProcessed), result.Count(pipeline.ItemSkipped), result.Count(pipeline.ItemFailed))
+	if canceled := result.Count(pipeline.ItemCanceled); canceled > 0 {
+		fmt.Printf(", %d canceled", canceled)
+	}
+	fmt.Println()
+	for _, item := range result.Items {
+		switch item.Status {
+		case pipeline.ItemFailed:
+			fmt.Fprintf(os.Stderr, "  failed:   %s: %v\n", item.Input, item.Err)
+		case pipeline.ItemSkipped:
+			fmt.Printf("  skipped:  %s: %v\n", item.Input, item.Err)
+		case pipeline.ItemCanceled:
+			fmt.Printf("  canceled: %s\n", item.Input)
+		}
+	}
+}
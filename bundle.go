@@ -0,0 +1,88 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/chezu/video-journal/internal/blog"
+	"github.com/chezu/video-journal/internal/pipeline"
+)
+
+// Fixed artifact names inside an --out-bundle directory
+const (
+	bundlePost           = "post.md"
+	bundleTranscript     = "transcript.txt"
+	bundleTranscriptJSON = "transcript.json"
+	bundleMeta           = "meta.json"
+	bundleKeywords       = "keywords.json"
+)
+
+// artifact is a file produced by a run
+type artifact struct {
+	path string
+	data []byte
+}
+
+// bundleMetadata describes a run in a bundle's meta.json
+type bundleMetadata struct {
+	Source         string    `json:"source"`
+	Title          string    `json:"title,omitempty"`
+	Model          string    `json:"model"`
+	Backend        string    `json:"backend"`
+	StyleGuide     string    `json:"style_guide"`
+	Language       string    `json:"language"`
+	Words          int       `json:"words"`
+	Sections       int       `json:"sections"`
+	ReadingMinutes int       `json:"reading_minutes"`
+	Generated      time.Time `json:"generated"`
+}
+
+// bundleDirFor returns the bundle directory for a video. A single input
+// writes straight into dir; in batch mode each video gets a subdirectory
+// named after it so the fixed artifact names don't collide.
+func bundleDirFor(dir, videoPath string, batch bool) string {
+	if !batch {
+		return dir
+	}
+	name := filepath.Base(videoPath)
+	return filepath.Join(dir, strings.TrimSuffix(name, filepath.Ext(name)))
+}
+
+// bundleArtifacts returns the extra files written next to the post in an
+// --out-bundle directory
+func bundleArtifacts(cfg config, videoPath, transcript, blogPost string) ([]artifact, error) {
+	postStats := blog.Stats(blog.StripExplainComments(blogPost))
+	meta, err := json.MarshalIndent(bundleMetadata{
+		Source:         videoPath,
+		Title:          blog.Title(blogPost),
+		Model:          cfg.Transcribe.ModelSize,
+		Backend:        pipeline.TranscribeBackend(cfg.Transcribe) + "+" + pipeline.BlogBackend,
+		StyleGuide:     describeStyle(cfg.Blog.StylePath),
+		Language:       cfg.Blog.Language,
+		Words:          postStats.Words,
+		Sections:       postStats.Sections,
+		ReadingMinutes: postStats.ReadingMinutes,
+		Generated:      time.Now().UTC().Truncate(time.Second),
+	}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode bundle metadata: %w", err)
+	}
+
+	return []artifact{
+		{path: filepath.Join(cfg.bundleDir, bundleTranscript), data: []byte(transcript + "\n")},
+		{path: filepath.Join(cfg.bundleDir, bundleMeta), data: append(meta, '\n')},
+	}, nil
+}
+
+// writeArtifacts writes each artifact with the configured file mode
+func writeArtifacts(artifacts []artifact, cfg config) error {
+	for _, a := range artifacts {
+		if err := writeOutputFile(a.path, a.data, cfg); err != nil {
+			return fmt.Errorf("failed to write %s: %w", a.path, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/chezu/video-journal/internal/transcribe"
+)
+
+// runCleanTemp implements the clean-temp subcommand, which removes temp files
+// left behind by runs that crashed or were killed
+func runCleanTemp(args []string) error {
+	fset := flag.NewFlagSet("clean-temp", flag.ExitOnError)
+	dirFlag := fset.String("temp-dir", os.TempDir(), "Temp directory to sweep (default: $TMPDIR or the system temp dir)")
+	olderThanFlag := fset.Duration("older-than", 24*time.Hour, "Only remove leftovers last modified longer ago than this, so running jobs are not disturbed")
+	dryRunFlag := fset.Bool("dry-run", false, "List what would be removed without deleting anything")
+	fset.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: video-journal clean-temp [flags]\n\n")
+		fmt.Fprintf(os.Stderr, "Remove leftover temp files (%s*) from interrupted runs.\n\n", strings.Join(transcribe.TempPrefixes, "*, "))
+		fmt.Fprintf(os.Stderr, "Flags:\n")
+		fset.PrintDefaults()
+	}
+	fset.Parse(args)
+
+	if fset.NArg() != 0 {
+		fset.Usage()
+		os.Exit(1)
+	}
+	if *olderThanFlag < 0 {
+		return fmt.Errorf("--older-than must not be negative")
+	}
+
+	entries, err := os.ReadDir(*dirFlag)
+	if err != nil {
+		return fmt.Errorf("failed to read temp directory: %w", err)
+	}
+
+	cutoff := time.Now().Add(-*olderThanFlag)
+	var removed int
+	var reclaimed int64
+	for _, entry := range entries {
+		if !hasTempPrefix(entry.Name()) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || info.ModTime().After(cutoff) {
+			continue
+		}
+
+		path := filepath.Join(*dirFlag, entry.Name())
+		size := diskUsage(path, info)
+		if *dryRunFlag {
+			fmt.Printf("Would remove %s (%s)\n", path, formatSize(size))
+		} else {
+			if err := os.RemoveAll(path); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to remove %s: %v\n", path, err)
+				continue
+			}
+			fmt.Printf("Removed %s (%s)\n", path, formatSize(size))
+		}
+		removed++
+		reclaimed += size
+	}
+
+	verb := "Removed"
+	if *dryRunFlag {
+		verb = "Would remove"
+	}
+	fmt.Printf("%s %d leftover(s), reclaiming %s\n", verb, removed, formatSize(reclaimed))
+	return nil
+}
+
+// hasTempPrefix reports whether name looks like one of our temp files
+func hasTempPrefix(name string) bool {
+	for _, prefix := range transcribe.TempPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// diskUsage returns the size of a file, or the total size of the files in a
+// directory
+func diskUsage(path string, info os.FileInfo) int64 {
+	if !info.IsDir() {
+		return info.Size()
+	}
+	var total int64
+	filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if fi, err := d.Info(); err == nil && !d.IsDir() {
+			total += fi.Size()
+		}
+		return nil
+	})
+	return total
+}
+
+// formatSize renders a byte count for humans (e.g. 1.5 MB)
+func formatSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
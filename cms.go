@@ -0,0 +1,77 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/chezu/video-journal/internal/blog"
+)
+
+// cmsExcerptLength is the excerpt size for --cms-json, in bytes
+const cmsExcerptLength = 280
+
+// cmsPost is the import format of the headless CMS used with --cms-json.
+// The field names match its schema exactly.
+type cmsPost struct {
+	Title      string   `json:"title"`
+	Slug       string   `json:"slug"`
+	Body       string   `json:"body"`
+	Excerpt    string   `json:"excerpt"`
+	Tags       []string `json:"tags"`
+	Date       string   `json:"date"`
+	CoverImage string   `json:"coverImage"`
+}
+
+// buildCMSPost assembles the CMS record for a post. The title goes in its
+// own field, so it is removed from the body. A post with no prose paragraph
+// falls back to its reading time for the excerpt.
+func buildCMSPost(post string, tags []string, coverImage string, date time.Time) ([]byte, error) {
+	post = blog.StripExplainComments(post)
+	title := blog.Title(post)
+	record := cmsPost{
+		Title:      title,
+		Slug:       blog.Slug(title),
+		Body:       blog.StripTitle(post),
+		Excerpt:    blog.Excerpt(post, cmsExcerptLength),
+		Tags:       tags,
+		Date:       date.UTC().Format(time.RFC3339),
+		CoverImage: coverImage,
+	}
+	if record.Excerpt == "" {
+		record.Excerpt = fmt.Sprintf("%d min read", blog.Stats(post).ReadingMinutes)
+	}
+	if record.Tags == nil {
+		record.Tags = []string{}
+	}
+
+	var missing []string
+	for _, field := range []struct{ name, value string }{
+		{"title", record.Title}, {"slug", record.Slug}, {"body", record.Body}, {"excerpt", record.Excerpt}, {"date", record.Date},
+	} {
+		if strings.TrimSpace(field.value) == "" {
+			missing = append(missing, field.name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("CMS record is missing required fields: %s", strings.Join(missing, ", "))
+	}
+
+	data, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode CMS record: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+// parseTags splits a comma-separated tag list, dropping empty entries
+func parseTags(s string) []string {
+	var tags []string
+	for _, tag := range strings.Split(s, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
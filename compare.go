@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/chezu/video-journal/internal/transcribe"
+)
+
+// runCompare transcribes one video with several models and prints each
+// transcript followed by a summary of timing and how far each model's wording
+// differs from the first model's
+func runCompare(ctx context.Context, videoPath string, models []string, opts transcribe.Options) error {
+	fmt.Printf("Comparing models %s on: %s\n", strings.Join(models, ", "), videoPath)
+	if opts.SampleDuration > 0 {
+		fmt.Printf("Using the first %v of audio\n", opts.SampleDuration)
+	}
+
+	results, err := transcribe.CompareModels(ctx, videoPath, models, opts)
+	if err != nil {
+		return err
+	}
+
+	for _, r := range results {
+		fmt.Printf("\n=== %s (%v) ===\n", r.Model, r.Elapsed.Round(time.Millisecond))
+		if r.Err != nil {
+			fmt.Printf("failed: %v\n", r.Err)
+			continue
+		}
+		fmt.Println(r.Transcript)
+	}
+
+	// The first successful model is the baseline for the diff ratio
+	baseline := -1
+	for i, r := range results {
+		if r.Err == nil {
+			baseline = i
+			break
+		}
+	}
+
+	fmt.Printf("\n%-16s %10s %8s %12s\n", "MODEL", "TIME", "WORDS", "DIFF")
+	for i, r := range results {
+		if r.Err != nil {
+			fmt.Printf("%-16s %10v %8s %12s\n", r.Model, r.Elapsed.Round(time.Millisecond), "-", "failed")
+			continue
+		}
+		diff := "baseline"
+		if i != baseline {
+			diff = fmt.Sprintf("%.1f%%", 100*transcribe.DiffRatio(results[baseline].Transcript, r.Transcript))
+		}
+		fmt.Printf("%-16s %10v %8d %12s\n", r.Model, r.Elapsed.Round(time.Millisecond), len(strings.Fields(r.Transcript)), diff)
+	}
+	fmt.Println("\nDIFF is the share of words changed relative to the baseline model (word-level edit distance).")
+	return nil
+}
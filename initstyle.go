@@ -0,0 +1,171 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// styleTones maps tone presets to the guidance written for them. Other
+// answers are written into the guide as given.
+var styleTones = map[string][]string{
+	"conversational": {"Conversational and approachable", "Professional but not stiff", "Enthusiastic without being over-the-top"},
+	"professional":   {"Clear, confident, and measured", "Avoid slang and filler", "Favor precision over personality"},
+	"technical":      {"Precise and matter-of-fact", "Explain the why behind each step", "Prefer concrete numbers and examples over adjectives"},
+	"playful":        {"Light-hearted and energetic", "Humor is welcome where it doesn't obscure the point", "Talk to the reader like a friend"},
+}
+
+// styleLengths maps length preferences to target word counts
+var styleLengths = map[string]string{
+	"short":  "around 600 words",
+	"medium": "around 1200 words",
+	"long":   "2000 words or more, covering the topic in depth",
+}
+
+// styleFormats maps formatting conventions to style guide rules
+var styleFormats = map[string]string{
+	"code-blocks": "Include code blocks with language tags for code examples",
+	"inline-code": "Use `code formatting` for technical terms, commands, or file names",
+	"bullets":     "Include bullet points for lists and key takeaways",
+	"bold-terms":  "Use **bold** for emphasis on key terms",
+	"tables":      "Use tables to compare options side by side",
+	"callouts":    "Use > blockquotes for tips and warnings",
+}
+
+// runInitStyle implements the init-style subcommand, which writes a starter
+// style guide from a few answers. Unset answers are asked for interactively
+// when stdin is a terminal and take their defaults otherwise.
+func runInitStyle(args []string) error {
+	fs := flag.NewFlagSet("init-style", flag.ExitOnError)
+	toneFlag := fs.String("tone", "", "Tone of the posts: "+strings.Join(sortedKeys(styleTones), ", ")+", or your own words (default conversational)")
+	audienceFlag := fs.String("audience", "", "Who the posts are for (default: developers)")
+	lengthFlag := fs.String("length", "", "Preferred post length: short, medium, or long (default medium)")
+	formatFlag := fs.String("format", "", "Comma-separated formatting conventions: "+strings.Join(sortedKeys(styleFormats), ", ")+" (default code-blocks,inline-code,bullets,bold-terms)")
+	outputFlag := fs.String("output", "style_guide.md", "Style guide file to write")
+	forceFlag := fs.Bool("force", false, "Overwrite the style guide if it exists")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: video-journal init-style [flags]\n\n")
+		fmt.Fprintf(os.Stderr, "Create a style guide by answering a few questions.\n\n")
+		fmt.Fprintf(os.Stderr, "Flags:\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 0 {
+		fs.Usage()
+		os.Exit(1)
+	}
+	if _, err := os.Stat(*outputFlag); err == nil && !*forceFlag {
+		return fmt.Errorf("style guide already exists: %s\nUse --force to overwrite", *outputFlag)
+	}
+
+	// An empty answer or end of input keeps the default
+	interactive := isTerminal(os.Stdin)
+	reader := bufio.NewReader(os.Stdin)
+	ask := func(answer *string, question, def string) {
+		if *answer != "" {
+			return
+		}
+		*answer = def
+		if !interactive {
+			return
+		}
+		fmt.Printf("%s [%s]: ", question, def)
+		line, _ := reader.ReadString('\n')
+		if line = strings.TrimSpace(line); line != "" {
+			*answer = line
+		}
+	}
+	for _, q := range []struct {
+		answer        *string
+		question, def string
+	}{
+		{toneFlag, "Tone (" + strings.Join(sortedKeys(styleTones), ", ") + ", or describe it)", "conversational"},
+		{audienceFlag, "Who are the posts for", "developers"},
+		{lengthFlag, "Post length (short, medium, long)", "medium"},
+		{formatFlag, "Formatting conventions (" + strings.Join(sortedKeys(styleFormats), ", ") + ")", "code-blocks,inline-code,bullets,bold-terms"},
+	} {
+		ask(q.answer, q.question, q.def)
+	}
+
+	guide, err := buildStyleGuide(*toneFlag, *audienceFlag, *lengthFlag, *formatFlag)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(*outputFlag, []byte(guide), 0644); err != nil {
+		return fmt.Errorf("failed to write style guide: %w", err)
+	}
+	fmt.Printf("Style guide saved to: %s\n", *outputFlag)
+	fmt.Println("Edit it to taste; it is plain markdown.")
+	return nil
+}
+
+// buildStyleGuide renders the answers as a markdown style guide in the same
+// shape as the bundled style_guide.md
+func buildStyleGuide(tone, audience, length, formats string) (string, error) {
+	lengthRule, ok := styleLengths[strings.ToLower(length)]
+	if !ok {
+		return "", fmt.Errorf("invalid length '%s'. Use short, medium, or long", length)
+	}
+	var formatRules []string
+	for _, f := range strings.Split(formats, ",") {
+		if f = strings.ToLower(strings.TrimSpace(f)); f == "" {
+			continue
+		}
+		rule, ok := styleFormats[f]
+		if !ok {
+			return "", fmt.Errorf("unknown formatting convention '%s'. Use: %s", f, strings.Join(sortedKeys(styleFormats), ", "))
+		}
+		formatRules = append(formatRules, rule)
+	}
+
+	toneRules, ok := styleTones[strings.ToLower(tone)]
+	if !ok {
+		toneRules = []string{tone}
+	}
+
+	var b strings.Builder
+	b.WriteString("# Writing Style Guide\n\n")
+	b.WriteString("Customize this file to match your personal writing style. The AI will use these guidelines when converting your video transcripts into blog posts.\n")
+
+	writeSection := func(title string, rules []string) {
+		if len(rules) == 0 {
+			return
+		}
+		fmt.Fprintf(&b, "\n## %s\n", title)
+		for _, rule := range rules {
+			fmt.Fprintf(&b, "- %s\n", rule)
+		}
+	}
+	writeSection("Tone", toneRules)
+	writeSection("Audience", []string{
+		"Write for " + audience,
+		"Explain background the audience may not have, and skip what they already know",
+	})
+	writeSection("Structure", []string{
+		"Aim for " + lengthRule,
+		"Use clear, descriptive headings (H2 for main sections, H3 for subsections)",
+		"Keep paragraphs short (2-4 sentences)",
+		"Add a brief introduction and conclusion",
+	})
+	writeSection("Formatting", formatRules)
+	writeSection("Content Guidelines", []string{
+		"Preserve specific examples and anecdotes from the transcript",
+		"Add context that might be obvious in video but not in text",
+		"End with clear takeaways or next steps for the reader",
+	})
+	return b.String(), nil
+}
+
+// sortedKeys returns a map's keys in order, for stable help text
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,163 @@
+package blog
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Span is a timed piece of the transcript used to place video anchors
+type Span struct {
+	Start int64 // Offset from the start of the video in milliseconds
+	Text  string
+}
+
+// Matching thresholds for AddVideoAnchors, chosen to leave paragraphs
+// unanchored rather than link them to the wrong moment
+const (
+	anchorWindow    = 4   // Consecutive spans compared with each paragraph
+	anchorMinWords  = 6   // Distinct content words a paragraph needs to be considered
+	anchorMinScore  = 0.5 // Share of the paragraph's words found in the best window
+	anchorMinMargin = 1.5 // How much the best window must beat the best unrelated one
+)
+
+// AddVideoAnchors appends a timestamp link such as [[12:34]](URL#t=754) to
+// each prose paragraph that clearly comes from one region of the transcript.
+// A paragraph is anchored only when enough of its words appear in a short run
+// of spans and no distant part of the transcript matches nearly as well.
+// Headings, lists, quotes, tables, and code are left alone.
+func AddVideoAnchors(post string, spans []Span, baseURL string) string {
+	if len(spans) == 0 {
+		return post
+	}
+	windows := make([]map[string]bool, len(spans))
+	for i := range spans {
+		windows[i] = map[string]bool{}
+		for j := i; j < i+anchorWindow && j < len(spans); j++ {
+			for _, w := range contentWords(spans[j].Text) {
+				windows[i][w] = true
+			}
+		}
+	}
+
+	lines := strings.Split(post, "\n")
+	var paragraph []int // Indexes of the current paragraph's lines
+	flush := func() {
+		if len(paragraph) == 0 {
+			return
+		}
+		var text []string
+		for _, i := range paragraph {
+			text = append(text, lines[i])
+		}
+		if start, ok := matchSpan(strings.Join(text, " "), windows, spans); ok {
+			last := paragraph[len(paragraph)-1]
+			lines[last] += fmt.Sprintf(" [[%s]](%s#t=%d)", formatTimestamp(start), baseURL, start/1000)
+		}
+		paragraph = nil
+	}
+
+	inFence := false
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") {
+			flush()
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "*Source:") ||
+			strings.HasPrefix(trimmed, "- ") || strings.HasPrefix(trimmed, "* ") ||
+			strings.HasPrefix(trimmed, ">") || strings.HasPrefix(trimmed, "|") ||
+			strings.HasPrefix(trimmed, "<!--") {
+			flush()
+			continue
+		}
+		paragraph = append(paragraph, i)
+	}
+	flush()
+
+	return strings.Join(lines, "\n")
+}
+
+// matchSpan finds the transcript window that a paragraph clearly comes from
+// and returns its start time
+func matchSpan(paragraph string, windows []map[string]bool, spans []Span) (int64, bool) {
+	words := contentWords(paragraph)
+	if len(words) < anchorMinWords {
+		return 0, false
+	}
+
+	scores := make([]float64, len(windows))
+	best := 0
+	for i, window := range windows {
+		hits := 0
+		for _, w := range words {
+			if window[w] {
+				hits++
+			}
+		}
+		scores[i] = float64(hits) / float64(len(words))
+		if scores[i] > scores[best] {
+			best = i
+		}
+	}
+	if scores[best] < anchorMinScore {
+		return 0, false
+	}
+
+	// Windows overlapping the best one share its spans, so only compare
+	// against windows elsewhere in the transcript
+	runnerUp := 0.0
+	for i, score := range scores {
+		if (i <= best-anchorWindow || i >= best+anchorWindow) && score > runnerUp {
+			runnerUp = score
+		}
+	}
+	if scores[best] < runnerUp*anchorMinMargin {
+		return 0, false
+	}
+
+	// Start at the first span in the window that shares a word, since ties
+	// favor earlier windows that begin before the matching region
+	inParagraph := map[string]bool{}
+	for _, w := range words {
+		inParagraph[w] = true
+	}
+	for j := best; j < best+anchorWindow && j < len(spans); j++ {
+		for _, w := range contentWords(spans[j].Text) {
+			if inParagraph[w] {
+				return spans[j].Start, true
+			}
+		}
+	}
+	return spans[best].Start, true
+}
+
+// contentWords returns the distinct lowercase words of text, skipping short
+// words that carry little meaning
+func contentWords(text string) []string {
+	seen := map[string]bool{}
+	var words []string
+	for _, w := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if len([]rune(w)) < 4 || seen[w] {
+			continue
+		}
+		seen[w] = true
+		words = append(words, w)
+	}
+	return words
+}
+
+// formatTimestamp renders milliseconds as m:ss, or h:mm:ss past an hour
+func formatTimestamp(ms int64) string {
+	s := ms / 1000
+	if s >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", s/3600, s/60%60, s%60)
+	}
+	return fmt.Sprintf("%d:%02d", s/60, s%60)
+}
@@ -0,0 +1,95 @@
+package blog
+
+import "testing"
+
+func TestAddVideoAnchors(t *testing.T) {
+	const url = "https://example.com/video"
+	const paragraph = "We start by installing ffmpeg on Ubuntu using the package manager."
+	spans := []Span{
+		{Start: 0, Text: "Welcome back to the channel everyone"},
+		{Start: 10000, Text: "today we are installing ffmpeg on ubuntu"},
+		{Start: 20000, Text: "using the package manager"},
+		{Start: 30000, Text: "it only takes a minute"},
+		{Start: 40000, Text: "next let's talk about kubernetes"},
+		{Start: 50000, Text: "clusters need careful deployment planning"},
+		{Start: 60000, Text: "helm charts simplify releases"},
+		{Start: 70000, Text: "thanks for watching, see you later"},
+	}
+	repeated := append(append([]Span{}, spans...),
+		Span{Start: 80000, Text: "recap: installing ffmpeg on ubuntu"},
+		Span{Start: 90000, Text: "using the package manager again"},
+	)
+
+	tests := []struct {
+		name  string
+		post  string
+		spans []Span
+		want  string
+	}{
+		{
+			name:  "anchors a clear match at its first matching span",
+			post:  paragraph + "\n",
+			spans: spans,
+			want:  paragraph + " [[0:10]](" + url + "#t=10)\n",
+		},
+		{
+			name:  "anchors the last line of a multi-line paragraph",
+			post:  "We start by installing ffmpeg\non Ubuntu using the package manager.\n",
+			spans: spans,
+			want:  "We start by installing ffmpeg\non Ubuntu using the package manager. [[0:10]](" + url + "#t=10)\n",
+		},
+		{
+			name:  "skips a match repeated elsewhere in the transcript",
+			post:  paragraph + "\n",
+			spans: repeated,
+			want:  paragraph + "\n",
+		},
+		{
+			name:  "skips a paragraph too short to anchor",
+			post:  "Installing ffmpeg on Ubuntu.\n",
+			spans: spans,
+			want:  "Installing ffmpeg on Ubuntu.\n",
+		},
+		{
+			name:  "skips a paragraph that matches weakly",
+			post:  "Careful planning makes every release simpler for everyone involved.\n",
+			spans: spans,
+			want:  "Careful planning makes every release simpler for everyone involved.\n",
+		},
+		{
+			name:  "leaves headings, lists, and code blocks untouched",
+			post:  "# Installing ffmpeg on Ubuntu using the package manager\n\n- installing ffmpeg on ubuntu using the package manager\n\n```\ninstalling ffmpeg on ubuntu using the package manager\n```\n",
+			spans: spans,
+			want:  "# Installing ffmpeg on Ubuntu using the package manager\n\n- installing ffmpeg on ubuntu using the package manager\n\n```\ninstalling ffmpeg on ubuntu using the package manager\n```\n",
+		},
+		{
+			name:  "no spans",
+			post:  paragraph + "\n",
+			spans: nil,
+			want:  paragraph + "\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := AddVideoAnchors(tt.post, tt.spans, url); got != tt.want {
+				t.Errorf("AddVideoAnchors(%q) = %q, want %q", tt.post, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatTimestamp(t *testing.T) {
+	tests := []struct {
+		ms   int64
+		want string
+	}{
+		{ms: 0, want: "0:00"},
+		{ms: 754999, want: "12:34"},
+		{ms: 3723000, want: "1:02:03"},
+	}
+	for _, tt := range tests {
+		if got := formatTimestamp(tt.ms); got != tt.want {
+			t.Errorf("formatTimestamp(%d) = %q, want %q", tt.ms, got, tt.want)
+		}
+	}
+}
@@ -2,44 +2,147 @@ package blog
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"os/exec"
 	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 )
 
 const (
-	ClaudeTimeout      = 10 * time.Minute // Claude CLI timeout
-	MaxTranscriptSize  = 500000           // ~500KB max transcript to send to Claude
+	ClaudeTimeout        = 10 * time.Minute // Claude CLI timeout
+	MaxTranscriptSize    = 500000           // ~500KB max transcript to send to Claude
+	DefaultMaxOutputSize = 1024 * 1024      // 1MB max generated post
+	MaxRepeatedLines     = 10               // Times a non-trivial line may recur before output is rejected
 )
 
+// Options configures blog generation
+type Options struct {
+	StylePath     string // Style guide path (see loadStyleGuide)
+	MaxOutputSize int    // Maximum generated post size in bytes (default: DefaultMaxOutputSize)
+	Language      string // Language code to write the post in; empty means the transcript's language
+	Usage         *Usage // Accumulates reported Claude CLI cost (optional)
+
+	// CoveredTopics summarizes earlier posts in a series; the prompt asks the
+	// model to focus on material not already covered
+	CoveredTopics []string
+
+	// Explain asks for an HTML comment after each section citing the part of
+	// the transcript it came from (see StripExplainComments)
+	Explain bool
+
+	// ContextDir is the working directory for Claude CLI, letting it read
+	// project docs there; the prompt lists the files it contains
+	ContextDir string
+
+	// SystemPrompt sets the model's role separately from the transcript and
+	// instructions (passed to Claude CLI as --append-system-prompt)
+	SystemPrompt string
+}
+
+// languageNames maps common language codes to names for the prompt
+var languageNames = map[string]string{
+	"en": "English", "es": "Spanish", "fr": "French", "de": "German",
+	"it": "Italian", "pt": "Portuguese", "nl": "Dutch", "ja": "Japanese",
+	"zh": "Chinese", "ko": "Korean", "ru": "Russian", "pl": "Polish",
+}
+
+// languageName returns a readable name for a language code, or the code itself
+func languageName(code string) string {
+	if name, ok := languageNames[code]; ok {
+		return name
+	}
+	return code
+}
+
 // ConvertToBlog converts a transcript into a blog post using Claude CLI
-func ConvertToBlog(transcript string, styleGuidePath string) (string, error) {
+func ConvertToBlog(ctx context.Context, transcript string, opts Options) (string, error) {
 	// Validate transcript size
 	if len(transcript) > MaxTranscriptSize {
 		return "", fmt.Errorf("transcript too large: %d bytes (max: %d bytes)", len(transcript), MaxTranscriptSize)
 	}
 
 	// Load style guide
-	styleGuide, err := loadStyleGuide(styleGuidePath)
+	styleGuide, err := loadStyleGuide(opts.StylePath)
 	if err != nil {
 		return "", err
 	}
 
 	// Build the prompt
-	prompt := buildPrompt(transcript, styleGuide)
+	prompt := buildPrompt(transcript, styleGuide, opts)
 
 	fmt.Println("Generating blog post with Claude CLI...")
+	result, err := runClaude(ctx, prompt, opts)
+	if err != nil {
+		return "", err
+	}
+
+	if err := checkOutput(result, "generated", opts); err != nil {
+		return "", err
+	}
+
+	return result, nil
+}
+
+// checkOutput guards against runaway generations: posts over the size limit
+// or repeating themselves. kind describes the post in the error message.
+func checkOutput(post, kind string, opts Options) error {
+	maxOutputSize := opts.MaxOutputSize
+	if maxOutputSize <= 0 {
+		maxOutputSize = DefaultMaxOutputSize
+	}
+	if len(post) > maxOutputSize {
+		return fmt.Errorf("%s post too large: %d bytes (max: %d bytes)", kind, len(post), maxOutputSize)
+	}
+	return checkRepetition(post, kind)
+}
+
+// checkRepetition rejects output where the same substantial line recurs more
+// than MaxRepeatedLines times, a sign the model got stuck in a loop. Short
+// lines (separators, list stubs) and fenced code are ignored.
+func checkRepetition(post, kind string) error {
+	counts := make(map[string]int)
+	inFence := false
+	for _, line := range strings.Split(post, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "```") {
+			inFence = !inFence
+			continue
+		}
+		if inFence || len(line) < 20 {
+			continue
+		}
+		counts[line]++
+		if counts[line] > MaxRepeatedLines {
+			return fmt.Errorf("%s post looks degenerate: line repeated more than %d times: %q", kind, MaxRepeatedLines, line)
+		}
+	}
+	return nil
+}
 
+// runClaude executes the claude CLI with the given prompt and returns its
+// trimmed, non-empty output. The CLI runs in opts.ContextDir when set, and the
+// cost it reports is added to opts.Usage. Canceling ctx stops the CLI.
+func runClaude(ctx context.Context, prompt string, opts Options) (string, error) {
 	// Create context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), ClaudeTimeout)
+	ctx, cancel := context.WithTimeout(ctx, ClaudeTimeout)
 	defer cancel()
 
-	// Execute claude CLI with the prompt
-	cmd := exec.CommandContext(ctx, "claude", "-p", prompt)
+	// Execute claude CLI with the prompt, using JSON output to get the cost
+	args := []string{"-p", prompt, "--output-format", "json"}
+	if opts.SystemPrompt != "" {
+		args = append(args, "--append-system-prompt", opts.SystemPrompt)
+	}
+	cmd := exec.CommandContext(ctx, "claude", args...)
+	cmd.Dir = opts.ContextDir
 	output, err := cmd.Output()
 	if err != nil {
+		if ctx.Err() == context.Canceled {
+			return "", fmt.Errorf("claude CLI canceled")
+		}
 		if ctx.Err() == context.DeadlineExceeded {
 			return "", fmt.Errorf("claude CLI timed out after %v", ClaudeTimeout)
 		}
@@ -49,8 +152,28 @@ func ConvertToBlog(transcript string, styleGuidePath string) (string, error) {
 		return "", fmt.Errorf("claude CLI error: %w", err)
 	}
 
+	var response struct {
+		Result       string  `json:"result"`
+		IsError      bool    `json:"is_error"`
+		TotalCostUSD float64 `json:"total_cost_usd"`
+		CostUSD      float64 `json:"cost_usd"` // Older CLI versions
+	}
+	if err := json.Unmarshal(output, &response); err != nil {
+		return "", fmt.Errorf("failed to parse claude CLI output: %w", err)
+	}
+
+	cost := response.TotalCostUSD
+	if cost == 0 {
+		cost = response.CostUSD
+	}
+	opts.Usage.add(cost)
+
+	if response.IsError {
+		return "", fmt.Errorf("claude CLI error: %s", response.Result)
+	}
+
 	// Validate output is non-empty
-	result := strings.TrimSpace(string(output))
+	result := strings.TrimSpace(response.Result)
 	if result == "" {
 		return "", fmt.Errorf("claude CLI returned empty output")
 	}
@@ -79,9 +202,37 @@ func loadStyleGuide(path string) (string, error) {
 		return "", fmt.Errorf("failed to read style guide: %w", err)
 	}
 
+	if !isLikelyText(data) {
+		return "", fmt.Errorf("style guide does not appear to be text: %s", path)
+	}
+
 	return string(data), nil
 }
 
+// maxNonPrintableRatio is the share of non-printable characters above which
+// content is treated as binary
+const maxNonPrintableRatio = 0.1
+
+// isLikelyText reports whether data looks like text: valid UTF-8 with no NUL
+// bytes and few non-printable characters
+func isLikelyText(data []byte) bool {
+	if !utf8.Valid(data) {
+		return false
+	}
+
+	total, nonPrintable := 0, 0
+	for _, r := range string(data) {
+		total++
+		if r == 0 {
+			return false
+		}
+		if r != '\n' && r != '\r' && r != '\t' && !unicode.IsPrint(r) {
+			nonPrintable++
+		}
+	}
+	return total == 0 || float64(nonPrintable)/float64(total) <= maxNonPrintableRatio
+}
+
 func getDefaultStyleGuide() string {
 	return `Write in a conversational, engaging tone.
 Use clear headings to organize the content.
@@ -90,23 +241,85 @@ Keep paragraphs short and scannable.
 Use active voice.`
 }
 
-func buildPrompt(transcript string, styleGuide string) string {
+// maxContextFiles caps how many context directory files the prompt lists
+const maxContextFiles = 50
+
+// listContextFiles returns the names of visible regular files in dir
+func listContextFiles(dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var files []string
+	for _, e := range entries {
+		if !e.Type().IsRegular() || strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		files = append(files, e.Name())
+		if len(files) == maxContextFiles {
+			break
+		}
+	}
+	return files
+}
+
+func buildPrompt(transcript string, styleGuide string, opts Options) string {
+	instructions := []string{
+		"Create an engaging title that captures the main topic",
+		"Write a brief introduction that hooks the reader",
+		"Organize the main content with clear headings",
+		"Preserve the key insights and examples from the transcript",
+		"Add a conclusion with key takeaways",
+		"Output the blog post in markdown format",
+		`Do not include phrases like "In this video" - write as if it was always a blog post`,
+	}
+	if opts.Language != "" {
+		instructions = append(instructions, fmt.Sprintf("Write the blog post in %s, translating from the transcript if needed", languageName(opts.Language)))
+	}
+	if opts.Explain {
+		instructions = append(instructions, "After each section, add an HTML comment of the form "+ExplainCommentPrefix+" ... --> briefly noting which part of the transcript (roughly where, and what was said) informed that section")
+	}
+	if len(opts.CoveredTopics) > 0 {
+		instructions = append(instructions, "Focus on material not listed under Previously Covered; refer back briefly instead of re-explaining it")
+	}
+
+	var projectContext string
+	if opts.ContextDir != "" {
+		if files := listContextFiles(opts.ContextDir); len(files) > 0 {
+			instructions = append(instructions, "Use the files listed under Project Context to get names, commands, and details right; the transcript remains the source of the post's content")
+			var b strings.Builder
+			b.WriteString("\n## Project Context\nThe working directory contains these files you may read:\n")
+			for _, f := range files {
+				fmt.Fprintf(&b, "- %s\n", f)
+			}
+			projectContext = b.String()
+		}
+	}
+
+	var numbered strings.Builder
+	for i, instruction := range instructions {
+		fmt.Fprintf(&numbered, "%d. %s\n", i+1, instruction)
+	}
+
+	var covered string
+	if len(opts.CoveredTopics) > 0 {
+		var b strings.Builder
+		b.WriteString("\n## Previously Covered (avoid repeating these topics)\n")
+		for _, topic := range opts.CoveredTopics {
+			fmt.Fprintf(&b, "- %s\n", topic)
+		}
+		covered = b.String()
+	}
+
 	return fmt.Sprintf(`Convert the following video transcript into a well-structured blog post.
 
 ## Style Guide
 %s
 
 ## Instructions
-1. Create an engaging title that captures the main topic
-2. Write a brief introduction that hooks the reader
-3. Organize the main content with clear headings
-4. Preserve the key insights and examples from the transcript
-5. Add a conclusion with key takeaways
-6. Output the blog post in markdown format
-7. Do not include phrases like "In this video" - write as if it was always a blog post
-
+%s%s%s
 ## Transcript
 %s
 
-## Blog Post (Markdown)`, styleGuide, transcript)
+## Blog Post (Markdown)`, styleGuide, numbered.String(), projectContext, covered, transcript)
 }
@@ -0,0 +1,59 @@
+package blog
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIsLikelyText(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want bool
+	}{
+		{"empty", []byte{}, true},
+		{"ascii markdown", []byte("# Style\n\n- Be concise\r\n\tIndented\n"), true},
+		{"utf-8 text", []byte("Écrivez simplement — 簡潔に書く 🎬\n"), true},
+		{"nul byte", []byte("text\x00more"), false},
+		{"invalid utf-8", []byte{0xff, 0xfe, 'h', 'i'}, false},
+		{"png header", []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"), false},
+		{"mostly control characters", []byte("a\x01\x02\x03\x04b"), false},
+		{"a few control characters", []byte("plain text with one bell\x07 in it"), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isLikelyText(tt.data); got != tt.want {
+				t.Errorf("isLikelyText(%q) = %v, want %v", tt.data, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadStyleGuideRejectsBinaryFiles(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr bool
+	}{
+		{"empty.md", nil, false},
+		{"utf8.md", []byte("# Guide\n\nÉcrivez simplement.\n"), false},
+		{"image.png", []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, tt.name)
+			if err := os.WriteFile(path, tt.data, 0644); err != nil {
+				t.Fatal(err)
+			}
+			guide, err := loadStyleGuide(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("loadStyleGuide(%s) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+			if err == nil && guide != string(tt.data) {
+				t.Errorf("loadStyleGuide(%s) = %q, want %q", tt.name, guide, tt.data)
+			}
+		})
+	}
+}
@@ -0,0 +1,249 @@
+package blog
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Keyword is a notable term in a post and how often it appears
+type Keyword struct {
+	Term  string `json:"term"`
+	Count int    `json:"count"`
+}
+
+// DefaultMaxKeywords is how many keywords ExtractKeywords returns by default
+const DefaultMaxKeywords = 15
+
+// headingWeight counts a term in a heading as this many mentions in prose
+const headingWeight = 3
+
+// stopwords are common English words that never make useful link targets:
+// function words plus the generic vocabulary of spoken walkthroughs
+var stopwords = toSet(`a about above actually after again against all almost also although always am among an and another
+any anything are around as at away back be became because been before being below between both but by can cannot
+could did do does doing done down during each easy either else enough even ever every example examples few first
+for from further get gets getting give go goes going gonna good got great had has have having he her here hers him
+his how however i if in instead into is it its itself just keep kind know last let like little lot lots made make
+makes making many may maybe me might more most much must my need needs new next no not nothing now of off often on
+once one only or other others our ours out over own part pretty probably put quite rather really right same see
+seems set she should show simply since so some something still such sure take than that the their theirs them then
+there these they thing things think this those though through thus time to today too two under until up us use used
+uses using very via want wanna was way ways we well were what when where whether which while who whom why will with
+within without work works would yeah yes yet you your yours yourself`)
+
+// ExtractKeywords picks out the post's notable terms by frequency, for
+// building internal links. Stopwords, numbers, and words under three letters
+// are dropped, code blocks and link targets are ignored, and heading words
+// count extra. Capitalized phrases inside sentences (e.g. "Visual Studio
+// Code") are kept together as entities. Terms are deduplicated ignoring case
+// and a trailing plural "s"; each is reported in its most common spelling.
+// Other terms must appear in prose twice, or once plus in a heading.
+// Returns at most max keywords, most frequent first.
+func ExtractKeywords(post string, max int) []Keyword {
+	if max <= 0 {
+		max = DefaultMaxKeywords
+	}
+
+	type term struct {
+		count    int            // Mentions, with heading mentions weighted
+		prose    int            // Mentions outside headings
+		entity   bool           // Seen as a capitalized name mid-sentence
+		spelling map[string]int // Spellings by count; those capitalized by position count 0
+	}
+	terms := map[string]*term{}
+	// atStart marks a word opening a sentence, whose capital says nothing
+	// about how the term is spelled
+	add := func(word string, heading, entity, atStart bool) {
+		key := strings.ToLower(word)
+		t := terms[key]
+		if t == nil {
+			t = &term{spelling: map[string]int{}}
+			terms[key] = t
+		}
+		t.entity = t.entity || entity
+		if heading {
+			t.count += headingWeight
+			t.spelling[word] += 0
+			return
+		}
+		t.count++
+		t.prose++
+		if atStart {
+			t.spelling[word] += 0
+		} else {
+			t.spelling[word]++
+		}
+	}
+
+	inFence := false
+	for _, line := range strings.Split(StripExplainComments(post), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") {
+			inFence = !inFence
+			continue
+		}
+		if inFence || trimmed == "" {
+			continue
+		}
+		heading := strings.HasPrefix(trimmed, "#")
+
+		words := keywordTokens(stripLinkTargets(trimmed))
+		sentenceStart := true
+		var phrase []string
+		flushPhrase := func() {
+			if len(phrase) > 0 {
+				add(strings.Join(phrase, " "), heading, true, false)
+			}
+			phrase = nil
+		}
+		for _, w := range words {
+			if w.text == "" {
+				flushPhrase()
+				sentenceStart = sentenceStart || w.endsSentence
+				continue
+			}
+			// Keep C++ and Node.js intact but not heading marks or trailing periods
+			word := strings.TrimLeft(strings.TrimRight(w.text, ".-"), "#.-+")
+			if word == "" {
+				flushPhrase() // A list bullet or heading mark starts a sentence
+				sentenceStart = true
+				continue
+			}
+			lower := strings.ToLower(word)
+			useful := !stopwords[lower] && len([]rune(word)) >= 3 && hasLetter(word)
+
+			// Capitalized words mid-sentence are names; headings are often
+			// title case, so only prose counts
+			capitalized := unicode.IsUpper([]rune(word)[0])
+			if capitalized && !sentenceStart && !heading && useful {
+				phrase = append(phrase, word)
+			} else {
+				flushPhrase()
+				if useful {
+					add(word, heading, false, sentenceStart)
+				}
+			}
+			sentenceStart = w.endsSentence
+		}
+		flushPhrase()
+	}
+
+	// Fold plurals into the singular when both appear
+	for key, t := range terms {
+		singular := strings.TrimSuffix(key, "s")
+		if singular == key {
+			continue
+		}
+		if s, ok := terms[singular]; ok {
+			s.count += t.count
+			s.prose += t.prose
+			s.entity = s.entity || t.entity
+			for spelling, n := range t.spelling {
+				s.spelling[strings.TrimSuffix(spelling, "s")] += n
+			}
+			delete(terms, key)
+		}
+	}
+
+	var keywords []Keyword
+	for _, t := range terms {
+		// Generic words rarely repeat in prose; a heading word must also
+		// appear there to count
+		if t.prose < 2 && !t.entity && !(t.prose == 1 && t.count > 1) {
+			continue
+		}
+		keywords = append(keywords, Keyword{Term: mostCommon(t.spelling), Count: t.count})
+	}
+	sort.Slice(keywords, func(i, j int) bool {
+		if keywords[i].Count != keywords[j].Count {
+			return keywords[i].Count > keywords[j].Count
+		}
+		return strings.ToLower(keywords[i].Term) < strings.ToLower(keywords[j].Term)
+	})
+	if len(keywords) > max {
+		keywords = keywords[:max]
+	}
+	return keywords
+}
+
+// keywordToken is a word in a line of text. A token with empty text marks
+// punctuation, which breaks up phrases.
+type keywordToken struct {
+	text         string
+	endsSentence bool // The word is followed by sentence-ending punctuation
+}
+
+// keywordTokens splits text into words, keeping characters that commonly
+// appear inside technical terms (Node.js, C++, gpt-4, C#)
+func keywordTokens(text string) []keywordToken {
+	var tokens []keywordToken
+	var word strings.Builder
+	flush := func(end rune) {
+		if word.Len() > 0 {
+			w := word.String()
+			tokens = append(tokens, keywordToken{
+				text:         w,
+				endsSentence: strings.HasSuffix(w, ".") || end == '!' || end == '?' || end == ':',
+			})
+			word.Reset()
+		}
+		if end != ' ' && end != '\t' && end != 0 {
+			tokens = append(tokens, keywordToken{endsSentence: end == '!' || end == '?' || end == ':'})
+		}
+	}
+	for _, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune(".-+#", r) {
+			word.WriteRune(r)
+		} else {
+			flush(r)
+		}
+	}
+	flush(0)
+	return tokens
+}
+
+// stripLinkTargets removes the URL part of markdown links so addresses
+// aren't counted as words
+func stripLinkTargets(line string) string {
+	var b strings.Builder
+	for {
+		i := strings.Index(line, "](")
+		if i < 0 {
+			b.WriteString(line)
+			return b.String()
+		}
+		b.WriteString(line[:i+1])
+		end := strings.IndexByte(line[i:], ')')
+		if end < 0 {
+			return b.String()
+		}
+		line = line[i+end+1:]
+	}
+}
+
+// hasLetter reports whether s contains a letter
+func hasLetter(s string) bool {
+	return strings.IndexFunc(s, unicode.IsLetter) >= 0
+}
+
+// mostCommon returns the spelling seen most often, preferring the
+// alphabetically first on ties for stable output
+func mostCommon(spellings map[string]int) string {
+	best, bestCount := "", -1
+	for s, n := range spellings {
+		if n > bestCount || (n == bestCount && s < best) {
+			best, bestCount = s, n
+		}
+	}
+	return best
+}
+
+// toSet splits a whitespace-separated word list into a set
+func toSet(words string) map[string]bool {
+	set := map[string]bool{}
+	for _, w := range strings.Fields(words) {
+		set[w] = true
+	}
+	return set
+}
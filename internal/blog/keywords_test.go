@@ -0,0 +1,75 @@
+package blog
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtractKeywords(t *testing.T) {
+	tests := []struct {
+		name string
+		post string
+		max  int
+		want []Keyword
+	}{
+		{
+			name: "filters stopwords and generic words",
+			post: "We really just want to use the thing. We really just want to use the thing.\n",
+			want: nil,
+		},
+		{
+			name: "counts repeated terms and folds case and plurals",
+			post: "Caching helps. The cache is warm. Caches expire.\n",
+			want: []Keyword{{Term: "cache", Count: 2}},
+		},
+		{
+			name: "orders ties alphabetically",
+			post: "zebra apple mango. mango zebra apple.\n",
+			want: []Keyword{{Term: "apple", Count: 2}, {Term: "mango", Count: 2}, {Term: "zebra", Count: 2}},
+		},
+		{
+			name: "orders by count before name",
+			post: "zebra zebra zebra apple apple.\n",
+			want: []Keyword{{Term: "zebra", Count: 3}, {Term: "apple", Count: 2}},
+		},
+		{
+			name: "applies the limit",
+			post: "zebra zebra zebra apple apple mango mango.\n",
+			max:  2,
+			want: []Keyword{{Term: "zebra", Count: 3}, {Term: "apple", Count: 2}},
+		},
+		{
+			name: "keeps capitalized names together",
+			post: "Today I tried Visual Studio Code for the first time.\n",
+			want: []Keyword{{Term: "Visual Studio Code", Count: 1}},
+		},
+		{
+			name: "ignores fenced code and link targets",
+			post: "Read [the guide](https://example.com/kubernetes).\n\n```\nkubernetes kubernetes\n```\n",
+			want: nil,
+		},
+		{
+			name: "weights headings",
+			post: "## Docker\n\nWe use docker here.\n",
+			want: []Keyword{{Term: "docker", Count: 1 + headingWeight}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExtractKeywords(tt.post, tt.max); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ExtractKeywords() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractKeywordsDefaultLimit(t *testing.T) {
+	var post string
+	for _, word := range []string{"alpha", "bravo", "charlie", "delta", "echo", "foxtrot", "golf", "hotel",
+		"india", "juliet", "kilo", "lima", "mike", "november", "oscar", "papa", "quebec", "romeo"} {
+		post += word + " " + word + ". "
+	}
+	if got := ExtractKeywords(post, 0); len(got) != DefaultMaxKeywords {
+		t.Errorf("ExtractKeywords() returned %d keywords, want %d", len(got), DefaultMaxKeywords)
+	}
+}
@@ -0,0 +1,36 @@
+package blog
+
+import (
+	"context"
+	"fmt"
+)
+
+// LintStyle asks Claude CLI which rules of the style guide a draft post
+// follows or violates. It is a diagnostic for iterating on style guides; the
+// returned critique is meant for the user, not for publishing.
+func LintStyle(ctx context.Context, post string, opts Options) (string, error) {
+	styleGuide, err := loadStyleGuide(opts.StylePath)
+	if err != nil {
+		return "", err
+	}
+
+	fmt.Println("Checking draft against the style guide with Claude CLI...")
+	prompt := fmt.Sprintf(`Review how well the following draft blog post follows its style guide.
+
+## Style Guide
+%s
+
+## Instructions
+1. Go through the style guide rule by rule
+2. For each rule, say whether the draft followed it, violated it, or whether it did not apply
+3. Quote a short passage from the draft as evidence for each violation
+4. End with the rules that seem too vague to have an effect on the draft
+5. Output only the review as markdown, with no preamble
+
+## Draft Post
+%s
+
+## Review`, styleGuide, post)
+
+	return runClaude(ctx, prompt, opts)
+}
@@ -0,0 +1,316 @@
+package blog
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// AddSourceLink inserts a provenance line pointing at the source video
+// directly under the post's H1 title. If the post has no H1, the line is
+// added at the top, after any YAML front matter. Headings inside fenced
+// code are ignored.
+func AddSourceLink(post string, sourceURL string) string {
+	sourceLine := fmt.Sprintf("*Source: [video](%s)*", sourceURL)
+
+	lines := strings.Split(post, "\n")
+	body := frontMatterEnd(lines)
+	insertAt := func(i int, block ...string) string {
+		out := make([]string, 0, len(lines)+len(block))
+		out = append(out, lines[:i]...)
+		out = append(out, block...)
+		out = append(out, lines[i:]...)
+		return strings.Join(out, "\n")
+	}
+
+	if i := titleLine(lines); i >= 0 {
+		return insertAt(i+1, "", sourceLine)
+	}
+
+	if body > 0 {
+		if body < len(lines) && strings.TrimSpace(lines[body]) != "" {
+			return insertAt(body, "", sourceLine, "")
+		}
+		return insertAt(body, "", sourceLine)
+	}
+	return sourceLine + "\n\n" + post
+}
+
+// frontMatterEnd returns the index of the first line after YAML front
+// matter, or 0 if the post has none. Front matter must open on the first
+// line and close with another --- line.
+func frontMatterEnd(lines []string) int {
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) != "---" {
+		return 0
+	}
+	for i := 1; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == "---" {
+			return i + 1
+		}
+	}
+	return 0 // Unterminated: not front matter after all
+}
+
+// titleLine returns the index of the post's first H1 heading, skipping front
+// matter and fenced code, or -1 if there is none
+func titleLine(lines []string) int {
+	inFence := false
+	for i := frontMatterEnd(lines); i < len(lines); i++ {
+		if strings.HasPrefix(strings.TrimSpace(lines[i]), "```") {
+			inFence = !inFence
+			continue
+		}
+		if !inFence && strings.HasPrefix(lines[i], "# ") {
+			return i
+		}
+	}
+	return -1
+}
+
+// Title returns the text of the post's first H1 heading, or "" if none
+func Title(post string) string {
+	lines := strings.Split(post, "\n")
+	if i := titleLine(lines); i >= 0 {
+		return strings.TrimSpace(strings.TrimPrefix(lines[i], "# "))
+	}
+	return ""
+}
+
+// Excerpt returns the first prose paragraph of the post, skipping headings,
+// lists, quotes, and code, truncated to roughly maxLen bytes on a word
+// boundary
+func Excerpt(post string, maxLen int) string {
+	var paragraph []string
+	inFence := false
+	for _, line := range strings.Split(post, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		if trimmed == "" {
+			if len(paragraph) > 0 {
+				break
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "*Source:") ||
+			strings.HasPrefix(trimmed, "- ") || strings.HasPrefix(trimmed, "* ") ||
+			strings.HasPrefix(trimmed, ">") || strings.HasPrefix(trimmed, "|") {
+			if len(paragraph) > 0 {
+				break
+			}
+			continue
+		}
+		paragraph = append(paragraph, trimmed)
+	}
+
+	text := strings.Join(paragraph, " ")
+	text = strings.NewReplacer("**", "", "__", "", "`", "").Replace(text)
+	if len(text) <= maxLen {
+		return text
+	}
+	for maxLen > 0 && !utf8.RuneStart(text[maxLen]) {
+		maxLen-- // Don't split a multibyte character
+	}
+	cut := strings.LastIndex(text[:maxLen], " ")
+	if cut <= 0 {
+		cut = maxLen
+	}
+	return strings.TrimRight(text[:cut], ",;:") + "..."
+}
+
+// ExplainCommentPrefix starts the HTML comments added by --explain
+const ExplainCommentPrefix = "<!-- explain:"
+
+// explainCommentPattern matches an explain annotation and surrounding spaces
+var explainCommentPattern = regexp.MustCompile(`(?s)[ \t]*<!--\s*explain:.*?-->[ \t]*`)
+
+// blankRunPattern matches three or more consecutive newlines
+var blankRunPattern = regexp.MustCompile(`\n{3,}`)
+
+// StripExplainComments removes the editor's-note annotations added with
+// Options.Explain, leaving a post ready to publish
+func StripExplainComments(post string) string {
+	stripped := explainCommentPattern.ReplaceAllString(post, "")
+	stripped = blankRunPattern.ReplaceAllString(stripped, "\n\n")
+	return strings.TrimSpace(stripped)
+}
+
+// headingPattern matches an ATX heading, capturing its level and text
+var headingPattern = regexp.MustCompile(`^(#{1,6})[ \t]+(.*?)(?:[ \t]+#+)?[ \t]*$`)
+
+// FlattenHeadings converts headings deeper than maxDepth into bold
+// paragraphs, keeping the post's structure shallow. Fenced code blocks are
+// left untouched. A maxDepth below 1 disables flattening.
+func FlattenHeadings(post string, maxDepth int) string {
+	if maxDepth < 1 {
+		return post
+	}
+
+	lines := strings.Split(post, "\n")
+	inFence := false
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		m := headingPattern.FindStringSubmatch(line)
+		if m == nil || len(m[1]) <= maxDepth {
+			continue
+		}
+		lines[i] = "**" + m[2] + "**"
+	}
+	return strings.Join(lines, "\n")
+}
+
+// listItemPattern matches a bullet or numbered list item, capturing its
+// indentation, marker, and text
+var listItemPattern = regexp.MustCompile(`^([ \t]*)([-*+]|\d+[.)])[ \t]+(\S.*)$`)
+
+// maxBlankLines is the longest run of blank lines NormalizeWhitespace keeps
+const maxBlankLines = 2
+
+// NormalizeWhitespace tidies a post for markdown linters: it trims trailing
+// spaces (keeping two where they mark a hard line break within a
+// paragraph), collapses runs of
+// three or more blank lines to two, expands tabs in list indentation to
+// spaces, puts a single space after list markers, and ends the post with
+// exactly one newline. Fenced code blocks are left untouched.
+func NormalizeWhitespace(post string) string {
+	var out []string
+	inFence := false
+	blanks := 0
+	lines := strings.Split(post, "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+			out = append(out, strings.TrimRight(line, " \t"))
+			blanks = 0
+			continue
+		}
+		if inFence {
+			out = append(out, line)
+			continue
+		}
+
+		trimmed := strings.TrimRight(line, " \t")
+		if trimmed != "" && strings.HasSuffix(line, "  ") && i+1 < len(lines) && continuesParagraph(lines[i+1]) {
+			trimmed += "  " // Hard line break
+		}
+		line = trimmed
+		if line == "" {
+			if blanks++; blanks > maxBlankLines {
+				continue
+			}
+			out = append(out, line)
+			continue
+		}
+		blanks = 0
+
+		if m := listItemPattern.FindStringSubmatch(line); m != nil {
+			line = expandTabs(m[1]) + m[2] + " " + m[3]
+		}
+		out = append(out, line)
+	}
+	return strings.Trim(strings.Join(out, "\n"), "\n") + "\n"
+}
+
+// continuesParagraph reports whether line is more text in the paragraph
+// before it, so a hard line break ahead of it has an effect
+func continuesParagraph(line string) bool {
+	line = strings.TrimSpace(line)
+	return line != "" && !strings.HasPrefix(line, "```") && !headingPattern.MatchString(line)
+}
+
+// expandTabs replaces tabs in indentation with spaces up to the next
+// four-column tab stop, as CommonMark does
+func expandTabs(indent string) string {
+	var b strings.Builder
+	for _, r := range indent {
+		if r == '\t' {
+			b.WriteString(strings.Repeat(" ", 4-b.Len()%4))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// maxSlugLength caps Slug output so URLs stay readable
+const maxSlugLength = 80
+
+// Slug turns a title into a lowercase, hyphen-separated URL path segment
+func Slug(title string) string {
+	var b strings.Builder
+	pendingDash := false
+	for _, r := range strings.ToLower(title) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if pendingDash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			pendingDash = false
+			b.WriteRune(r)
+			continue
+		}
+		pendingDash = true
+	}
+	slug := b.String()
+	if len(slug) > maxSlugLength {
+		slug = slug[:maxSlugLength]
+		if cut := strings.LastIndex(slug, "-"); cut > 0 {
+			slug = slug[:cut]
+		}
+		for !utf8.ValidString(slug) {
+			slug = slug[:len(slug)-1]
+		}
+	}
+	return slug
+}
+
+// StripTitle removes the post's first H1 heading and the blank lines after
+// it, for destinations that store the title separately
+func StripTitle(post string) string {
+	lines := strings.Split(post, "\n")
+	if i := titleLine(lines); i >= 0 {
+		rest := strings.Join(lines[i+1:], "\n")
+		return strings.TrimSpace(strings.Join(lines[:i], "\n") + "\n" + rest)
+	}
+	return post
+}
+
+// frontMatterTitle matches the title line of YAML front matter
+var frontMatterTitle = regexp.MustCompile(`^title:\s*(.*?)\s*$`)
+
+// AddTitleAffixes wraps the post's title in prefix and suffix, updating both
+// the first H1 heading and the title in leading YAML front matter (when the
+// post has them) so the two stay consistent. Reports whether a title was found.
+func AddTitleAffixes(post, prefix, suffix string) (string, bool) {
+	lines := strings.Split(post, "\n")
+	found := false
+
+	body := frontMatterEnd(lines)
+	for i := 1; i < body-1; i++ {
+		if m := frontMatterTitle.FindStringSubmatch(lines[i]); m != nil {
+			title := strings.Trim(m[1], `"'`)
+			lines[i] = fmt.Sprintf("title: %q", prefix+title+suffix)
+			found = true
+			break
+		}
+	}
+
+	if i := titleLine(lines); i >= 0 {
+		title := strings.TrimSpace(strings.TrimPrefix(lines[i], "# "))
+		lines[i] = "# " + prefix + title + suffix
+		found = true
+	}
+	return strings.Join(lines, "\n"), found
+}
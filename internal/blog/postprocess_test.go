@@ -0,0 +1,219 @@
+package blog
+
+import (
+	"testing"
+	"unicode/utf8"
+)
+
+func TestNormalizeWhitespace(t *testing.T) {
+	tests := []struct {
+		name string
+		post string
+		want string
+	}{
+		{
+			name: "trims trailing spaces",
+			post: "# Title \t\n\nText   \n",
+			want: "# Title\n\nText\n",
+		},
+		{
+			name: "keeps hard line breaks",
+			post: "First line  \nsecond line\n",
+			want: "First line  \nsecond line\n",
+		},
+		{
+			name: "trims a hard break that ends a paragraph",
+			post: "First paragraph  \n\nSecond  \n## Heading  \n\n```\ncode\n```\n",
+			want: "First paragraph\n\nSecond\n## Heading\n\n```\ncode\n```\n",
+		},
+		{
+			name: "trims a hard break before a fence",
+			post: "Intro  \n```\ncode\n```\n",
+			want: "Intro\n```\ncode\n```\n",
+		},
+		{
+			name: "collapses three or more blank lines to two",
+			post: "One\n\n\n\n\nTwo\n\n\nThree\n\nFour\n",
+			want: "One\n\n\nTwo\n\n\nThree\n\nFour\n",
+		},
+		{
+			name: "normalizes list indentation and marker spacing",
+			post: "-   Item\n\t*\tNested\n1.  Numbered\n",
+			want: "- Item\n    * Nested\n1. Numbered\n",
+		},
+		{
+			name: "ends with a single newline",
+			post: "\n\nText\n\n\n",
+			want: "Text\n",
+		},
+		{
+			name: "leaves fenced code untouched",
+			post: "Intro\n\n```go\nx := 1   \n\n\n\n\tif x {\n-  not a list\n```\n",
+			want: "Intro\n\n```go\nx := 1   \n\n\n\n\tif x {\n-  not a list\n```\n",
+		},
+		{
+			name: "resumes normalizing after the fence closes",
+			post: "```\ncode  \t\n```   \n\n\n\n\nAfter \n",
+			want: "```\ncode  \t\n```\n\n\nAfter\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizeWhitespace(tt.post); got != tt.want {
+				t.Errorf("NormalizeWhitespace(%q) = %q, want %q", tt.post, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFlattenHeadings(t *testing.T) {
+	post := "# Title\n\n## Setup\n\n### Install\n\n#### Details ###\n\nText\n"
+	tests := []struct {
+		name     string
+		post     string
+		maxDepth int
+		want     string
+	}{
+		{
+			name:     "flattens headings deeper than the limit",
+			post:     post,
+			maxDepth: 2,
+			want:     "# Title\n\n## Setup\n\n**Install**\n\n**Details**\n\nText\n",
+		},
+		{
+			name:     "depth 1 keeps only the title",
+			post:     post,
+			maxDepth: 1,
+			want:     "# Title\n\n**Setup**\n\n**Install**\n\n**Details**\n\nText\n",
+		},
+		{
+			name:     "zero disables flattening",
+			post:     post,
+			maxDepth: 0,
+			want:     post,
+		},
+		{
+			name:     "negative depth disables flattening",
+			post:     post,
+			maxDepth: -3,
+			want:     post,
+		},
+		{
+			name:     "depth beyond six keeps every heading",
+			post:     post,
+			maxDepth: 9,
+			want:     post,
+		},
+		{
+			name:     "leaves headings inside fenced code",
+			post:     "## Shell\n\n```bash\n### not a heading\n# comment\n```\n\n### After\n",
+			maxDepth: 1,
+			want:     "**Shell**\n\n```bash\n### not a heading\n# comment\n```\n\n**After**\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FlattenHeadings(tt.post, tt.maxDepth); got != tt.want {
+				t.Errorf("FlattenHeadings(%q, %d) = %q, want %q", tt.post, tt.maxDepth, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddTitleAffixes(t *testing.T) {
+	tests := []struct {
+		name      string
+		post      string
+		want      string
+		wantFound bool
+	}{
+		{
+			name:      "wraps the H1 title",
+			post:      "# Setup\n\nText\n",
+			want:      "# Part 1: Setup!\n\nText\n",
+			wantFound: true,
+		},
+		{
+			name:      "updates front matter and heading together",
+			post:      "---\ntitle: \"Setup\"\n---\n\n# Setup\n",
+			want:      "---\ntitle: \"Part 1: Setup!\"\n---\n\n# Part 1: Setup!\n",
+			wantFound: true,
+		},
+		{
+			name:      "skips a comment line inside fenced code",
+			post:      "Intro\n\n```bash\n# install deps\n```\n\n# Setup\n",
+			want:      "Intro\n\n```bash\n# install deps\n```\n\n# Part 1: Setup!\n",
+			wantFound: true,
+		},
+		{
+			name:      "reports a post whose only # line is code",
+			post:      "```python\n# not a title\n```\n",
+			want:      "```python\n# not a title\n```\n",
+			wantFound: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, found := AddTitleAffixes(tt.post, "Part 1: ", "!")
+			if got != tt.want || found != tt.wantFound {
+				t.Errorf("AddTitleAffixes(%q) = %q, %v, want %q, %v", tt.post, got, found, tt.want, tt.wantFound)
+			}
+		})
+	}
+}
+
+func TestTitleSkipsFencedCode(t *testing.T) {
+	post := "```bash\n# install deps\n```\n\n# Setup\n\nText"
+	if got := Title(post); got != "Setup" {
+		t.Errorf("Title() = %q, want %q", got, "Setup")
+	}
+	want := "```bash\n# install deps\n```\n\n\nText"
+	if got := StripTitle(post); got != want {
+		t.Errorf("StripTitle() = %q, want %q", got, want)
+	}
+}
+
+func TestExcerpt(t *testing.T) {
+	tests := []struct {
+		name   string
+		post   string
+		maxLen int
+		want   string
+	}{
+		{
+			name:   "returns a short paragraph whole",
+			post:   "# Title\n\nFirst paragraph.\n\nSecond.\n",
+			maxLen: 280,
+			want:   "First paragraph.",
+		},
+		{
+			name:   "cuts on a word boundary",
+			post:   "One two three four",
+			maxLen: 10,
+			want:   "One two...",
+		},
+		{
+			name:   "never splits a multibyte character",
+			post:   "日本語のテキスト",
+			maxLen: 7,
+			want:   "日本...",
+		},
+		{
+			name:   "backs off a partial rune before a space",
+			post:   "café au lait",
+			maxLen: 4,
+			want:   "caf...",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Excerpt(tt.post, tt.maxLen)
+			if got != tt.want {
+				t.Errorf("Excerpt(%q, %d) = %q, want %q", tt.post, tt.maxLen, got, tt.want)
+			}
+			if !utf8.ValidString(got) {
+				t.Errorf("Excerpt(%q, %d) = %q is not valid UTF-8", tt.post, tt.maxLen, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package blog
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// codeBlockPattern matches fenced code blocks, including the fences
+var codeBlockPattern = regexp.MustCompile("(?ms)^```.*?^```[ \t]*$")
+
+// codePlaceholder formats the stand-in text for the i-th code block
+func codePlaceholder(i int) string {
+	return fmt.Sprintf("@@CODE_BLOCK_%d@@", i)
+}
+
+// Proofread runs a light copyedit over a generated post using Claude CLI.
+// It fixes typos and inconsistent spellings without changing structure or
+// voice. Fenced code blocks are swapped out for placeholders before the
+// post is sent and restored verbatim afterwards.
+func Proofread(ctx context.Context, post string, opts Options) (string, error) {
+	styleGuide, err := loadStyleGuide(opts.StylePath)
+	if err != nil {
+		return "", err
+	}
+
+	var blocks []string
+	masked := codeBlockPattern.ReplaceAllStringFunc(post, func(block string) string {
+		blocks = append(blocks, block)
+		return codePlaceholder(len(blocks) - 1)
+	})
+
+	fmt.Println("Proofreading blog post with Claude CLI...")
+	result, err := runClaude(ctx, buildProofreadPrompt(masked, styleGuide), opts)
+	if err != nil {
+		return "", err
+	}
+
+	for i, block := range blocks {
+		placeholder := codePlaceholder(i)
+		if !strings.Contains(result, placeholder) {
+			return "", fmt.Errorf("proofread output dropped a code block (%s)", placeholder)
+		}
+		result = strings.Replace(result, placeholder, block, 1)
+	}
+	if err := checkOutput(result, "proofread", opts); err != nil {
+		return "", err
+	}
+
+	return result, nil
+}
+
+func buildProofreadPrompt(post string, styleGuide string) string {
+	return fmt.Sprintf(`Proofread the following markdown blog post.
+
+## Style Guide
+%s
+
+## Instructions
+1. Fix typos, spelling, and grammar mistakes
+2. Make spellings of names and products consistent throughout
+3. Do not change the structure, headings, voice, or meaning
+4. Keep every line of the form @@CODE_BLOCK_N@@ exactly as it is
+5. Output only the corrected markdown, with no commentary
+
+## Blog Post
+%s
+
+## Corrected Blog Post (Markdown)`, styleGuide, post)
+}
@@ -0,0 +1,44 @@
+package blog
+
+import (
+	"strings"
+)
+
+// WordsPerMinute is the reading speed used for reading time estimates
+const WordsPerMinute = 200
+
+// PostStats summarizes the size and shape of a post
+type PostStats struct {
+	Words          int
+	Sections       int // Number of H2 headings
+	ReadingMinutes int // Rounded up, at least 1 for a non-empty post
+}
+
+// Stats counts the words, sections, and reading time of a markdown post.
+// Fenced code is excluded from the word count.
+func Stats(post string) PostStats {
+	var stats PostStats
+	inFence := false
+	for _, line := range strings.Split(post, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		if strings.HasPrefix(trimmed, "## ") {
+			stats.Sections++
+		}
+		for _, word := range strings.Fields(trimmed) {
+			if strings.Trim(word, "#*-_>|`") != "" {
+				stats.Words++
+			}
+		}
+	}
+	if stats.Words > 0 {
+		stats.ReadingMinutes = (stats.Words + WordsPerMinute - 1) / WordsPerMinute
+	}
+	return stats
+}
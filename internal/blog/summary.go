@@ -0,0 +1,24 @@
+package blog
+
+import (
+	"context"
+	"fmt"
+)
+
+// SummarizePost asks Claude CLI for a brief summary of the topics a post
+// covers, used to steer later posts in a series away from repetition
+func SummarizePost(ctx context.Context, post string, usage *Usage) (string, error) {
+	prompt := fmt.Sprintf(`Summarize the topics covered by the following blog post.
+
+## Instructions
+1. Write 2-3 sentences listing the main topics, examples, and conclusions
+2. Be specific enough that a writer could avoid repeating the same material
+3. Output only the summary, with no preamble
+
+## Blog Post
+%s
+
+## Summary`, post)
+
+	return runClaude(ctx, prompt, Options{Usage: usage})
+}
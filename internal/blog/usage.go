@@ -0,0 +1,32 @@
+package blog
+
+import "sync"
+
+// Usage accumulates the cost reported by Claude CLI calls. It is safe for
+// concurrent use, and a nil *Usage ignores updates.
+type Usage struct {
+	mu      sync.Mutex
+	costUSD float64
+	calls   int
+}
+
+// add records one call and its reported cost
+func (u *Usage) add(costUSD float64) {
+	if u == nil {
+		return
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.costUSD += costUSD
+	u.calls++
+}
+
+// Cost returns the total reported cost in USD and the number of calls
+func (u *Usage) Cost() (float64, int) {
+	if u == nil {
+		return 0, 0
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.costUSD, u.calls
+}
@@ -0,0 +1,296 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Index files kept in the cache directory
+const (
+	outputsFile = "outputs.json" // Generated posts -> transcripts they were built from
+	indexFile   = "index.json"   // Source videos -> readable transcript files
+)
+
+// shortHashLen is the number of key characters in readable file names
+const shortHashLen = 12
+
+// Cache stores transcripts keyed by a hash of the source video and the
+// transcription settings. With Readable set, transcripts are named
+// <videobasename>-<shorthash>.txt and listed in index.json by source path.
+type Cache struct {
+	Dir      string
+	Readable bool
+	mu       sync.Mutex // Guards outputs.json and index.json
+}
+
+// IndexEntry locates the readable transcript for a source video
+type IndexEntry struct {
+	Key     string    `json:"key"`
+	File    string    `json:"file"` // Path relative to the cache directory
+	Updated time.Time `json:"updated"`
+}
+
+// OutputRecord links a generated post to its cached transcript
+type OutputRecord struct {
+	Key       string    `json:"key"`                  // Transcript cache key
+	Source    string    `json:"source"`               // Video the transcript came from
+	StyleHash string    `json:"style_hash,omitempty"` // Hash of the style guide used
+	Updated   time.Time `json:"updated"`
+}
+
+// DefaultDir returns the default cache directory
+func DefaultDir() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".cache", "video-journal")
+}
+
+// New opens the cache in dir, creating it if needed
+func New(dir string) (*Cache, error) {
+	if err := os.MkdirAll(filepath.Join(dir, "transcripts"), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	return &Cache{Dir: dir}, nil
+}
+
+// Key hashes a video's contents together with the settings that affect its
+// transcript, so changing either yields a different key
+func Key(videoPath string, settings string) (string, error) {
+	f, err := os.Open(videoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open video for hashing: %w", err)
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("failed to hash video: %w", err)
+	}
+	h.Write([]byte("\x00" + settings))
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// HashText returns a cache key for arbitrary text content
+func HashText(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:])
+}
+
+// Get returns the cached text stored under key in bucket, if present
+func (c *Cache) Get(bucket, key string) (string, bool, error) {
+	data, err := os.ReadFile(filepath.Join(c.Dir, bucket, key+".txt"))
+	if os.IsNotExist(err) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read cached %s: %w", bucket, err)
+	}
+	return string(data), true, nil
+}
+
+// Put stores text under key in bucket
+func (c *Cache) Put(bucket, key, text string) error {
+	dir := filepath.Join(c.Dir, bucket)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	if err := writeFileAtomic(filepath.Join(dir, key+".txt"), []byte(text)); err != nil {
+		return fmt.Errorf("failed to write cached %s: %w", bucket, err)
+	}
+	return nil
+}
+
+// Transcript returns the cached transcript for key, if present. Both hashed
+// and readable file names are checked.
+func (c *Cache) Transcript(key string) (string, bool, error) {
+	transcript, ok, err := c.Get("transcripts", key)
+	if ok || err != nil {
+		return transcript, ok, err
+	}
+
+	if len(key) < shortHashLen {
+		return "", false, nil
+	}
+	matches, err := filepath.Glob(filepath.Join(c.Dir, "transcripts", "*-"+key[:shortHashLen]+".txt"))
+	if err != nil || len(matches) == 0 {
+		return "", false, err
+	}
+	data, err := os.ReadFile(matches[0])
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read cached transcript: %w", err)
+	}
+	return string(data), true, nil
+}
+
+// PutTranscript stores the transcript of source under key
+func (c *Cache) PutTranscript(key, transcript, source string) error {
+	if !c.Readable {
+		return c.Put("transcripts", key, transcript)
+	}
+
+	base := filepath.Base(source)
+	name := readableName(strings.TrimSuffix(base, filepath.Ext(base))) + "-" + key[:shortHashLen] + ".txt"
+	file := filepath.Join("transcripts", name)
+	if err := writeFileAtomic(filepath.Join(c.Dir, file), []byte(transcript)); err != nil {
+		return fmt.Errorf("failed to cache transcript: %w", err)
+	}
+
+	absSource, err := filepath.Abs(source)
+	if err != nil {
+		return fmt.Errorf("invalid source path: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	index := make(map[string]IndexEntry)
+	if err := readJSON(filepath.Join(c.Dir, indexFile), &index); err != nil {
+		return err
+	}
+	index[absSource] = IndexEntry{Key: key, File: file, Updated: time.Now()}
+	return writeJSON(filepath.Join(c.Dir, indexFile), index)
+}
+
+// LookupSource finds the most recently cached transcript for a source video
+// whose file name, without extension, is name
+func (c *Cache) LookupSource(name string) (string, IndexEntry, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	index := make(map[string]IndexEntry)
+	if err := readJSON(filepath.Join(c.Dir, indexFile), &index); err != nil {
+		return "", IndexEntry{}, false, err
+	}
+
+	var source string
+	var found IndexEntry
+	for path, entry := range index {
+		base := filepath.Base(path)
+		if strings.TrimSuffix(base, filepath.Ext(base)) == name && entry.Updated.After(found.Updated) {
+			source, found = path, entry
+		}
+	}
+	return source, found, source != "", nil
+}
+
+// readableName reduces a video name to characters safe in file names
+func readableName(name string) string {
+	cleaned := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		}
+		return '_'
+	}, name)
+	if cleaned == "" {
+		cleaned = "video"
+	}
+	return cleaned
+}
+
+// readJSON decodes a JSON file into v; a missing file leaves v unchanged
+func readJSON(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", filepath.Base(path), err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
+
+// writeJSON encodes v and writes it to path atomically
+func writeJSON(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", filepath.Base(path), err)
+	}
+	if err := writeFileAtomic(path, append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
+
+// loadOutputs reads the output mapping; callers must hold c.mu
+func (c *Cache) loadOutputs() (map[string]OutputRecord, error) {
+	outputs := make(map[string]OutputRecord)
+	if err := readJSON(filepath.Join(c.Dir, outputsFile), &outputs); err != nil {
+		return nil, err
+	}
+	return outputs, nil
+}
+
+// RecordOutput remembers which cached transcript and style guide a post was
+// generated from
+func (c *Cache) RecordOutput(outputPath, key, source, styleHash string) error {
+	absOutput, err := filepath.Abs(outputPath)
+	if err != nil {
+		return fmt.Errorf("invalid output path: %w", err)
+	}
+	absSource, err := filepath.Abs(source)
+	if err != nil {
+		return fmt.Errorf("invalid source path: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	outputs, err := c.loadOutputs()
+	if err != nil {
+		return err
+	}
+	outputs[absOutput] = OutputRecord{Key: key, Source: absSource, StyleHash: styleHash, Updated: time.Now()}
+	return writeJSON(filepath.Join(c.Dir, outputsFile), outputs)
+}
+
+// LookupOutput returns the record for a previously generated post
+func (c *Cache) LookupOutput(outputPath string) (OutputRecord, bool, error) {
+	absOutput, err := filepath.Abs(outputPath)
+	if err != nil {
+		return OutputRecord{}, false, fmt.Errorf("invalid output path: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	outputs, err := c.loadOutputs()
+	if err != nil {
+		return OutputRecord{}, false, err
+	}
+	record, ok := outputs[absOutput]
+	return record, ok, nil
+}
+
+// writeFileAtomic writes data to a temp file and renames it into place
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
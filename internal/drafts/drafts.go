@@ -0,0 +1,179 @@
+package drafts
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Default staging location and queue file name
+const (
+	DefaultDir = "drafts"
+	QueueFile  = "queue.json"
+)
+
+// Draft statuses
+const (
+	StatusPending  = "pending"
+	StatusApproved = "approved"
+)
+
+// Entry records a staged draft awaiting approval
+type Entry struct {
+	Name     string     `json:"name"`   // Draft file name within the drafts directory
+	Source   string     `json:"source"` // Absolute path of the video the draft was generated from
+	Output   string     `json:"output"` // Absolute final location once approved
+	Status   string     `json:"status"`
+	Created  time.Time  `json:"created"`
+	Approved *time.Time `json:"approved,omitempty"`
+}
+
+// Queue is the contents of the queue file
+type Queue struct {
+	Entries []Entry `json:"entries"`
+}
+
+// Load reads the queue from dir. A missing queue file yields an empty queue.
+func Load(dir string) (*Queue, error) {
+	data, err := os.ReadFile(filepath.Join(dir, QueueFile))
+	if os.IsNotExist(err) {
+		return &Queue{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read draft queue: %w", err)
+	}
+
+	var q Queue
+	if err := json.Unmarshal(data, &q); err != nil {
+		return nil, fmt.Errorf("failed to parse draft queue %s: %w", filepath.Join(dir, QueueFile), err)
+	}
+	return &q, nil
+}
+
+// Save writes the queue to dir, replacing the previous file atomically
+func (q *Queue) Save(dir string) error {
+	data, err := json.MarshalIndent(q, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode draft queue: %w", err)
+	}
+
+	tmpPath := filepath.Join(dir, QueueFile+".tmp")
+	if err := os.WriteFile(tmpPath, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write draft queue: %w", err)
+	}
+	if err := os.Rename(tmpPath, filepath.Join(dir, QueueFile)); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write draft queue: %w", err)
+	}
+	return nil
+}
+
+// find returns the index of the entry with the given name, or -1
+func (q *Queue) find(name string) int {
+	for i, e := range q.Entries {
+		if e.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// mu serializes queue updates within this process
+var mu sync.Mutex
+
+// Stage writes content as a draft in dir and records it in the queue as
+// pending. The draft is named after the final output file; staging the same
+// name again replaces the earlier draft. Returns the draft path.
+func Stage(dir, source, output string, content []byte) (string, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create drafts directory: %w", err)
+	}
+
+	q, err := Load(dir)
+	if err != nil {
+		return "", err
+	}
+
+	// Record absolute paths so approve works from any directory
+	absOutput, err := filepath.Abs(output)
+	if err != nil {
+		return "", fmt.Errorf("invalid output path: %w", err)
+	}
+	absSource, err := filepath.Abs(source)
+	if err != nil {
+		return "", fmt.Errorf("invalid source path: %w", err)
+	}
+
+	name := filepath.Base(output)
+	draftPath := filepath.Join(dir, name)
+	if err := os.WriteFile(draftPath, content, 0644); err != nil {
+		return "", fmt.Errorf("failed to write draft: %w", err)
+	}
+
+	entry := Entry{
+		Name:    name,
+		Source:  absSource,
+		Output:  absOutput,
+		Status:  StatusPending,
+		Created: time.Now(),
+	}
+	if i := q.find(name); i >= 0 {
+		q.Entries[i] = entry
+	} else {
+		q.Entries = append(q.Entries, entry)
+	}
+
+	if err := q.Save(dir); err != nil {
+		return "", err
+	}
+	return draftPath, nil
+}
+
+// Approve moves a pending draft to its recorded output location and marks it
+// approved. name may omit the .md extension. An existing output file is only
+// replaced when force is set.
+func Approve(dir, name string, force bool) (Entry, error) {
+	q, err := Load(dir)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	i := q.find(name)
+	if i < 0 && !strings.HasSuffix(name, ".md") {
+		i = q.find(name + ".md")
+	}
+	if i < 0 {
+		return Entry{}, fmt.Errorf("no staged draft named %s", name)
+	}
+
+	entry := q.Entries[i]
+	if entry.Status != StatusPending {
+		return Entry{}, fmt.Errorf("draft %s is already %s", entry.Name, entry.Status)
+	}
+
+	if !force {
+		if _, err := os.Stat(entry.Output); err == nil {
+			return Entry{}, fmt.Errorf("output file already exists: %s\nUse --force to overwrite", entry.Output)
+		}
+	}
+	if err := os.Rename(filepath.Join(dir, entry.Name), entry.Output); err != nil {
+		return Entry{}, fmt.Errorf("failed to move draft: %w", err)
+	}
+
+	now := time.Now()
+	entry.Status = StatusApproved
+	entry.Approved = &now
+	q.Entries[i] = entry
+
+	if err := q.Save(dir); err != nil {
+		return Entry{}, err
+	}
+	return entry, nil
+}
@@ -0,0 +1,144 @@
+package feed
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// DefaultTitle is the title given to newly created feeds
+const DefaultTitle = "Video Journal"
+
+// DefaultAuthor is the author name given to newly created feeds
+const DefaultAuthor = "Video Journal"
+
+// Feed is an Atom feed document. Elements this package doesn't model
+// (subtitle, rights, ...) are kept in Extra so hand-edited feeds survive a
+// round trip.
+type Feed struct {
+	XMLName xml.Name     `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string       `xml:"title"`
+	ID      string       `xml:"id"`
+	Updated string       `xml:"updated"`
+	Author  *Person      `xml:"author,omitempty"`
+	Links   []Link       `xml:"link"`
+	Extra   []rawElement `xml:",any"`
+	Entries []Entry      `xml:"entry"`
+}
+
+// Person is an Atom person construct, used for the feed author
+type Person struct {
+	Name  string `xml:"name"`
+	URI   string `xml:"uri,omitempty"`
+	Email string `xml:"email,omitempty"`
+}
+
+// Entry is a single Atom entry
+type Entry struct {
+	Title   string       `xml:"title"`
+	ID      string       `xml:"id"`
+	Updated string       `xml:"updated"`
+	Link    Link         `xml:"link"`
+	Summary string       `xml:"summary"`
+	Extra   []rawElement `xml:",any"`
+}
+
+// Link is an Atom link element
+type Link struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+// rawElement preserves an unmodeled element verbatim
+type rawElement struct {
+	XMLName xml.Name
+	Attrs   []xml.Attr `xml:",any,attr"`
+	Inner   string     `xml:",innerxml"`
+}
+
+// NewEntry builds an entry for a post. The ID is derived from the link so
+// regenerating a post replaces its previous entry.
+func NewEntry(title, summary, link string, updated time.Time) Entry {
+	sum := sha256.Sum256([]byte(link))
+	return Entry{
+		Title:   title,
+		ID:      "urn:video-journal:" + hex.EncodeToString(sum[:8]),
+		Updated: updated.UTC().Format(time.RFC3339),
+		Link:    Link{Href: link, Rel: "alternate"},
+		Summary: summary,
+	}
+}
+
+// Load reads a feed file. If it doesn't exist, an empty feed skeleton is
+// returned with the given author (DefaultAuthor when empty) and a rel="self"
+// link to selfURL, the address the feed will be published at.
+func Load(path, author, selfURL string) (*Feed, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		if author == "" {
+			author = DefaultAuthor
+		}
+		absPath, _ := filepath.Abs(path)
+		sum := sha256.Sum256([]byte(absPath))
+		return &Feed{
+			Title:  DefaultTitle,
+			ID:     "urn:video-journal:feed:" + hex.EncodeToString(sum[:8]),
+			Author: &Person{Name: author},
+			Links:  []Link{{Href: selfURL, Rel: "self", Type: "application/atom+xml"}},
+		}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read feed: %w", err)
+	}
+
+	var f Feed
+	if err := xml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse feed %s: %w", path, err)
+	}
+	return &f, nil
+}
+
+// Add inserts an entry, replacing any entry with the same ID, and keeps
+// entries ordered newest first
+func (f *Feed) Add(entry Entry) {
+	entries := f.Entries[:0]
+	for _, e := range f.Entries {
+		if e.ID != entry.ID {
+			entries = append(entries, e)
+		}
+	}
+	f.Entries = append(entries, entry)
+
+	sort.SliceStable(f.Entries, func(i, j int) bool {
+		ti, _ := time.Parse(time.RFC3339, f.Entries[i].Updated)
+		tj, _ := time.Parse(time.RFC3339, f.Entries[j].Updated)
+		return ti.After(tj)
+	})
+	f.Updated = entry.Updated
+}
+
+// Save writes the feed to path, replacing the previous file atomically
+func (f *Feed) Save(path string) error {
+	data, err := xml.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode feed: %w", err)
+	}
+	data = append([]byte(xml.Header), data...)
+	data = append(data, '\n')
+
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write feed: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write feed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ItemStatus is the outcome of one batch item
+type ItemStatus string
+
+// Batch item outcomes
+const (
+	ItemProcessed ItemStatus = "processed"
+	ItemSkipped   ItemStatus = "skipped"
+	ItemFailed    ItemStatus = "failed"
+	ItemCanceled  ItemStatus = "canceled" // Interrupted or never started
+)
+
+// ItemResult describes how one batch item went
+type ItemResult struct {
+	Input    string
+	Status   ItemStatus
+	Err      error // Why the item failed, was skipped, or was interrupted
+	Duration time.Duration
+}
+
+// BatchResult lists every item of a batch run in input order
+type BatchResult struct {
+	Items []ItemResult
+}
+
+// Count returns how many items ended with the given status
+func (r BatchResult) Count(status ItemStatus) int {
+	n := 0
+	for _, item := range r.Items {
+		if item.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// Err returns an error if any item failed
+func (r BatchResult) Err() error {
+	if failed := r.Count(ItemFailed); failed > 0 {
+		return fmt.Errorf("%d of %d inputs failed", failed, len(r.Items))
+	}
+	return nil
+}
+
+// BatchOptions configures RunBatch
+type BatchOptions struct {
+	Workers  int              // Items processed at once; at least 1
+	FailFast bool             // Stop the batch at the first failure
+	IsSkip   func(error) bool // Reports errors that mean skipped rather than failed; nil treats every error as a failure
+}
+
+// RunBatch calls process for each input with up to opts.Workers running at
+// once and collects the outcomes. By default it continues past failures; with
+// FailFast the first failure cancels the context passed to items still
+// running and leaves the rest unstarted, all reported as canceled.
+func RunBatch(ctx context.Context, inputs []string, opts BatchOptions, process func(ctx context.Context, i int, input string) error) BatchResult {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	result := BatchResult{Items: make([]ItemResult, len(inputs))}
+	for i, input := range inputs {
+		result.Items[i] = ItemResult{Input: input, Status: ItemCanceled}
+	}
+
+	var wg sync.WaitGroup
+	queue := make(chan int)
+	for range min(max(opts.Workers, 1), len(inputs)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range queue {
+				start := time.Now()
+				err := process(ctx, i, inputs[i])
+
+				// Each worker writes only its own items, so no lock is needed
+				item := &result.Items[i]
+				item.Err = err
+				item.Duration = time.Since(start)
+				switch {
+				case err == nil:
+					item.Status = ItemProcessed
+				case ctx.Err() != nil:
+					item.Status = ItemCanceled
+				case opts.IsSkip != nil && opts.IsSkip(err):
+					item.Status = ItemSkipped
+				default:
+					item.Status = ItemFailed
+					if opts.FailFast {
+						cancel()
+					}
+				}
+			}
+		}()
+	}
+
+	for i := range inputs {
+		if ctx.Err() != nil {
+			break
+		}
+		select {
+		case queue <- i:
+		case <-ctx.Done():
+		}
+	}
+	close(queue)
+	wg.Wait()
+	return result
+}
@@ -0,0 +1,108 @@
+package pipeline
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/chezu/video-journal/internal/blog"
+	"github.com/chezu/video-journal/internal/transcribe"
+)
+
+// Stage names a pipeline stage
+type Stage string
+
+// Pipeline stages
+const (
+	StageTranscribe Stage = "transcribe"
+	StageBlog       Stage = "blog"
+)
+
+// Metrics describes a completed stage
+type Metrics struct {
+	Stage      Stage
+	Duration   time.Duration
+	InputSize  int64  // Bytes in: video file for transcription, transcript for blog
+	OutputSize int64  // Bytes out: transcript or post
+	Model      string // Whisper model, or the LLM for the blog stage
+	Backend    string // Tool that ran the stage (e.g. "whisper.cpp", "claude-cli")
+}
+
+// Options configures a pipeline run. The hooks are optional and let
+// embedders observe stages without parsing stdout; nil hooks are skipped.
+type Options struct {
+	Transcribe transcribe.Options
+	Blog       blog.Options
+
+	OnTranscribeDone func(Metrics)
+	OnBlogDone       func(Metrics)
+	OnStageError     func(Stage, error)
+}
+
+// Transcribe runs the transcription stage and reports it to the hooks
+func Transcribe(ctx context.Context, videoPath string, opts Options) (string, error) {
+	start := time.Now()
+	transcript, err := transcribe.TranscribeVideo(ctx, videoPath, opts.Transcribe)
+	if err != nil {
+		opts.stageError(StageTranscribe, err)
+		return "", err
+	}
+
+	if opts.OnTranscribeDone != nil {
+		var inputSize int64
+		if info, err := os.Stat(videoPath); err == nil {
+			inputSize = info.Size()
+		}
+		opts.OnTranscribeDone(Metrics{
+			Stage:      StageTranscribe,
+			Duration:   time.Since(start),
+			InputSize:  inputSize,
+			OutputSize: int64(len(transcript)),
+			Model:      opts.Transcribe.ModelSize,
+			Backend:    TranscribeBackend(opts.Transcribe),
+		})
+	}
+	return transcript, nil
+}
+
+// Blog runs the blog generation stage and reports it to the hooks
+func Blog(ctx context.Context, transcript string, opts Options) (string, error) {
+	start := time.Now()
+	post, err := blog.ConvertToBlog(ctx, transcript, opts.Blog)
+	if err != nil {
+		opts.stageError(StageBlog, err)
+		return "", err
+	}
+
+	if opts.OnBlogDone != nil {
+		opts.OnBlogDone(Metrics{
+			Stage:      StageBlog,
+			Duration:   time.Since(start),
+			InputSize:  int64(len(transcript)),
+			OutputSize: int64(len(post)),
+			Model:      "claude",
+			Backend:    BlogBackend,
+		})
+	}
+	return post, nil
+}
+
+// BlogBackend names the tool used for blog generation
+const BlogBackend = "claude-cli"
+
+// TranscribeBackend names the tool used for transcription
+func TranscribeBackend(opts transcribe.Options) string {
+	if fields := strings.Fields(opts.TranscriberCmd); len(fields) > 0 {
+		return filepath.Base(fields[0])
+	}
+	return "whisper.cpp"
+}
+
+// stageError reports a failed stage to the OnStageError hook
+func (o Options) stageError(stage Stage, err error) {
+	if o.OnStageError != nil {
+		o.OnStageError(stage, err)
+	}
+}
@@ -0,0 +1,115 @@
+package stats
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Header is the CSV schema written to new stats files
+var Header = []string{
+	"date", "video", "output", "words", "sections", "reading_minutes",
+	"model", "backend", "duration_seconds",
+}
+
+// Row is one generated post's entry in the stats file
+type Row struct {
+	Date           time.Time
+	Video          string
+	Output         string
+	Words          int
+	Sections       int
+	ReadingMinutes int
+	Model          string
+	Backend        string
+	Duration       time.Duration
+}
+
+// record formats the row in Header order
+func (r Row) record() []string {
+	return []string{
+		r.Date.Format(time.RFC3339),
+		r.Video,
+		r.Output,
+		strconv.Itoa(r.Words),
+		strconv.Itoa(r.Sections),
+		strconv.Itoa(r.ReadingMinutes),
+		r.Model,
+		r.Backend,
+		strconv.FormatFloat(r.Duration.Seconds(), 'f', 1, 64),
+	}
+}
+
+// Lock file settings for appends shared between processes
+const (
+	lockRetryInterval = 50 * time.Millisecond
+	lockTimeout       = 10 * time.Second
+	staleLockAge      = time.Minute // Locks older than this are left over from a crash
+)
+
+// mu serializes appends within this process
+var mu sync.Mutex
+
+// Append adds a row to the CSV file at path, writing the header first if the
+// file is new or empty. A sibling .lock file keeps concurrent processes from
+// interleaving writes.
+func Append(path string, row Row) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	unlock, err := lockFile(path + ".lock")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open stats file: %w", err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat stats file: %w", err)
+	}
+
+	w := csv.NewWriter(f)
+	if info.Size() == 0 {
+		w.Write(Header)
+	}
+	w.Write(row.record())
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return fmt.Errorf("failed to write stats file: %w", err)
+	}
+	return nil
+}
+
+// lockFile acquires an exclusive lock by creating lockPath, waiting for
+// other holders and clearing locks abandoned by crashed runs
+func lockFile(lockPath string) (func(), error) {
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			f.Close()
+			return func() { os.Remove(lockPath) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("failed to lock stats file: %w", err)
+		}
+
+		if info, statErr := os.Stat(lockPath); statErr == nil && time.Since(info.ModTime()) > staleLockAge {
+			os.Remove(lockPath)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out waiting for stats lock: %s", lockPath)
+		}
+		time.Sleep(lockRetryInterval)
+	}
+}
@@ -0,0 +1,112 @@
+package transcribe
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultChunkDuration is the length of each audio piece when falling back to
+// chunked transcription
+const DefaultChunkDuration = 10 * time.Minute
+
+// chunkDuration returns the configured chunk length or the default
+func chunkDuration(opts Options) time.Duration {
+	if opts.ChunkDuration > 0 {
+		return opts.ChunkDuration
+	}
+	return DefaultChunkDuration
+}
+
+// splitAudio cuts a WAV file into consecutive pieces of the given length
+// using ffmpeg's segment muxer. Returns the piece paths in order and a
+// cleanup function for the temp directory holding them.
+func splitAudio(ctx context.Context, audioPath string, chunk time.Duration, limits *Limits) ([]string, func(), error) {
+	dir, err := os.MkdirTemp("", tempChunksPrefix+"*")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create chunk directory: %w", err)
+	}
+	cleanup := func() {
+		os.RemoveAll(dir)
+	}
+
+	release, err := limits.ffmpegSlot(ctx)
+	if err != nil {
+		cleanup()
+		return nil, nil, fmt.Errorf("ffmpeg audio split canceled")
+	}
+	defer release()
+
+	ffmpegCtx, cancel := context.WithTimeout(ctx, FFmpegTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ffmpegCtx, "ffmpeg", "-y",
+		"-i", audioPath,
+		"-f", "segment",
+		"-segment_time", fmt.Sprintf("%.0f", chunk.Seconds()),
+		"-c", "copy",
+		filepath.Join(dir, "chunk-%04d.wav"),
+	)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		cleanup()
+		if ffmpegCtx.Err() == context.DeadlineExceeded {
+			return nil, nil, fmt.Errorf("ffmpeg audio split timed out after %v", FFmpegTimeout)
+		}
+		return nil, nil, fmt.Errorf("ffmpeg audio split failed: %w\nOutput: %s", err, string(output))
+	}
+
+	chunks, err := filepath.Glob(filepath.Join(dir, "chunk-*.wav"))
+	if err != nil || len(chunks) == 0 {
+		cleanup()
+		return nil, nil, fmt.Errorf("ffmpeg audio split produced no chunks")
+	}
+	sort.Strings(chunks)
+	return chunks, cleanup, nil
+}
+
+// runWhisperChunked transcribes audio piece by piece so each whisper run fits
+// within WhisperTimeout, then joins the results. Segment offsets are shifted
+// to be relative to the start of the full audio.
+func runWhisperChunked(ctx context.Context, audioPath string, opts Options) (*whisperOutput, error) {
+	chunk := chunkDuration(opts)
+	chunks, cleanup, err := splitAudio(ctx, audioPath, chunk, opts.Limits)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	var texts []string
+	var segments []Segment
+	for i, chunkPath := range chunks {
+		fmt.Printf("Transcribing chunk %d/%d...\n", i+1, len(chunks))
+		chunkOpts := opts
+		if opts.onProgress != nil {
+			// Scale each chunk's progress into its share of the whole
+			done := i
+			chunkOpts.onProgress = func(percent int) {
+				opts.onProgress((done*100 + percent) / len(chunks))
+			}
+		}
+		out, err := runWhisper(ctx, chunkPath, chunkOpts)
+		if err != nil {
+			return nil, fmt.Errorf("chunk %d/%d: %w", i+1, len(chunks), err)
+		}
+
+		if text := strings.TrimSpace(out.text); text != "" {
+			texts = append(texts, text)
+		}
+		offset := int64(i) * chunk.Milliseconds()
+		for _, seg := range out.segments {
+			seg.Start += offset
+			seg.End += offset
+			segments = append(segments, seg)
+		}
+	}
+
+	return &whisperOutput{text: strings.Join(texts, "\n"), segments: segments}, nil
+}
@@ -0,0 +1,93 @@
+package transcribe
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ModelResult is one model's transcript from CompareModels
+type ModelResult struct {
+	Model      string
+	Transcript string
+	Elapsed    time.Duration
+	Err        error // Set when this model failed; the others still run
+}
+
+// CompareModels transcribes the video with each model in turn, extracting the
+// audio only once. opts.ModelSize is ignored; other options apply to every run.
+func CompareModels(ctx context.Context, videoPath string, models []string, opts Options) ([]ModelResult, error) {
+	if err := checkVideo(videoPath); err != nil {
+		return nil, err
+	}
+	if err := checkRange(ctx, videoPath, opts); err != nil {
+		return nil, err
+	}
+	for _, model := range models {
+		if err := EnsureModel(opts.ModelDir, model); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := findWhisperCLI(); err != nil {
+		return nil, err
+	}
+
+	fmt.Println("Extracting audio from video...")
+	audioPath, audioCleanup, err := extractAudio(ctx, videoPath, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer audioCleanup()
+
+	results := make([]ModelResult, 0, len(models))
+	for _, model := range models {
+		fmt.Printf("Model %s: ", model)
+		modelOpts := opts
+		modelOpts.ModelSize = model
+
+		start := time.Now()
+		out, err := runWhisper(ctx, audioPath, modelOpts)
+		result := ModelResult{Model: model, Elapsed: time.Since(start), Err: err}
+		if err == nil {
+			result.Transcript = strings.TrimSpace(out.text)
+		}
+		results = append(results, result)
+
+		if ctx.Err() != nil {
+			return results, ctx.Err()
+		}
+	}
+	return results, nil
+}
+
+// DiffRatio is a rough measure of how much two transcripts differ: the
+// word-level edit distance divided by the longer transcript's word count.
+// 0 means identical wording and 1 means nothing in common.
+func DiffRatio(a, b string) float64 {
+	wordsA := strings.Fields(strings.ToLower(a))
+	wordsB := strings.Fields(strings.ToLower(b))
+	longest := max(len(wordsA), len(wordsB))
+	if longest == 0 {
+		return 0
+	}
+
+	// Levenshtein distance over words, keeping only the previous row
+	prev := make([]int, len(wordsB)+1)
+	curr := make([]int, len(wordsB)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(wordsA); i++ {
+		curr[0] = i
+		for j := 1; j <= len(wordsB); j++ {
+			cost := 1
+			if wordsA[i-1] == wordsB[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return float64(prev[len(wordsB)]) / float64(longest)
+}
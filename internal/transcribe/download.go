@@ -0,0 +1,171 @@
+package transcribe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ModelBaseURL is where whisper.cpp ggml models are downloaded from
+const ModelBaseURL = "https://huggingface.co/ggerganov/whisper.cpp/resolve/main"
+
+// Defaults for DownloadOptions
+const (
+	DefaultDownloadRetries = 3
+	DefaultDownloadTimeout = 30 * time.Minute // Per attempt; large models are ~3GB
+	maxDownloadBackoff     = 30 * time.Second
+)
+
+// DownloadOptions configures DownloadModel
+type DownloadOptions struct {
+	Retries int           // Attempts after the first that resume the partial file
+	Timeout time.Duration // Limit for each attempt; 0 means no limit
+}
+
+// ModelURL returns the download URL for a whisper model size
+func ModelURL(modelSize string) string {
+	return fmt.Sprintf("%s/ggml-%s.bin", ModelBaseURL, modelSize)
+}
+
+// DownloadModel fetches a whisper model into modelDir unless it is already
+// there. Data is written to a .part file next to the model; a failed attempt
+// is resumed with an HTTP Range request after an exponential backoff, and the
+// model is moved into place only once its size matches the server's.
+func DownloadModel(ctx context.Context, modelDir, modelSize string, opts DownloadOptions) error {
+	modelPath := ModelPath(modelDir, modelSize)
+	if _, err := os.Stat(modelPath); err == nil {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(modelPath), 0755); err != nil {
+		return fmt.Errorf("failed to create model directory: %w", err)
+	}
+
+	url := ModelURL(modelSize)
+	partPath := modelPath + ".part"
+	fmt.Printf("Downloading whisper model %s from %s\n", modelSize, url)
+
+	var err error
+	backoff := time.Second
+	for attempt := 0; attempt <= opts.Retries; attempt++ {
+		if attempt > 0 {
+			fmt.Printf("Download failed: %v\nRetrying in %v (attempt %d of %d)...\n", err, backoff, attempt, opts.Retries)
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("model download canceled")
+			case <-time.After(backoff):
+			}
+			backoff = min(backoff*2, maxDownloadBackoff)
+		}
+
+		err = downloadAttempt(ctx, url, partPath, opts.Timeout)
+		if err == nil {
+			if err := os.Rename(partPath, modelPath); err != nil {
+				return fmt.Errorf("failed to install model: %w", err)
+			}
+			fmt.Printf("Model saved to: %s\n", modelPath)
+			return nil
+		}
+		if ctx.Err() != nil {
+			return fmt.Errorf("model download canceled")
+		}
+	}
+	return fmt.Errorf("model download failed after %d attempt(s): %w (partial download kept at %s)", opts.Retries+1, err, partPath)
+}
+
+// errRestartDownload means the partial file can't be resumed and was removed
+var errRestartDownload = errors.New("partial download does not match the server's file; restarting")
+
+// downloadAttempt downloads url into partPath, resuming from its current size
+func downloadAttempt(ctx context.Context, url, partPath string, timeout time.Duration) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	var offset int64
+	if info, err := os.Stat(partPath); err == nil {
+		offset = info.Size()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	flags := os.O_CREATE | os.O_WRONLY
+	var total int64 = -1
+	switch resp.StatusCode {
+	case http.StatusOK:
+		// Full body: the server ignored the range or there was nothing to resume
+		offset = 0
+		flags |= os.O_TRUNC
+		total = resp.ContentLength
+	case http.StatusPartialContent:
+		fmt.Printf("Resuming download at %d bytes\n", offset)
+		flags |= os.O_APPEND
+		total = contentRangeTotal(resp.Header.Get("Content-Range"))
+		if total < 0 && resp.ContentLength >= 0 {
+			total = offset + resp.ContentLength
+		}
+	case http.StatusRequestedRangeNotSatisfiable:
+		// The partial file is already complete, or larger than the model
+		if total = contentRangeTotal(resp.Header.Get("Content-Range")); total == offset {
+			return nil
+		}
+		os.Remove(partPath)
+		return errRestartDownload
+	default:
+		return fmt.Errorf("unexpected HTTP status %s", resp.Status)
+	}
+
+	f, err := os.OpenFile(partPath, flags, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open partial download: %w", err)
+	}
+	written, copyErr := io.Copy(f, resp.Body)
+	if err := f.Close(); err != nil && copyErr == nil {
+		copyErr = err
+	}
+	if copyErr != nil {
+		return fmt.Errorf("download interrupted after %d bytes: %w", offset+written, copyErr)
+	}
+
+	if total >= 0 && offset+written != total {
+		if offset+written > total {
+			os.Remove(partPath)
+			return errRestartDownload
+		}
+		return fmt.Errorf("incomplete download: got %d of %d bytes", offset+written, total)
+	}
+	return nil
+}
+
+// contentRangeTotal returns the complete length from a Content-Range header
+// such as "bytes 100-199/3000" or "bytes */3000", or -1 if it is unknown
+func contentRangeTotal(header string) int64 {
+	i := strings.LastIndex(header, "/")
+	if i < 0 {
+		return -1
+	}
+	total, err := strconv.ParseInt(header[i+1:], 10, 64)
+	if err != nil {
+		return -1
+	}
+	return total
+}
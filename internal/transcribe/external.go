@@ -0,0 +1,54 @@
+package transcribe
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// TranscriberAudioPlaceholder marks where the audio path goes in TranscriberCmd
+const TranscriberAudioPlaceholder = "{audio}"
+
+// runTranscriberCmd runs an external transcription command on an extracted
+// audio file. The command is split on whitespace (no shell is involved) and
+// must print the transcript to stdout. It is bound by WhisperTimeout.
+func runTranscriberCmd(ctx context.Context, command string, audioPath string) (*whisperOutput, error) {
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("transcriber command is empty")
+	}
+
+	substituted := false
+	for i, f := range fields {
+		if strings.Contains(f, TranscriberAudioPlaceholder) {
+			fields[i] = strings.ReplaceAll(f, TranscriberAudioPlaceholder, audioPath)
+			substituted = true
+		}
+	}
+	if !substituted {
+		fields = append(fields, audioPath)
+	}
+
+	cmdCtx, cancel := context.WithTimeout(ctx, WhisperTimeout)
+	defer cancel()
+
+	fmt.Printf("Transcribing audio with %s...\n", fields[0])
+	cmd := exec.CommandContext(cmdCtx, fields[0], fields[1:]...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		if cmdCtx.Err() == context.Canceled {
+			return nil, fmt.Errorf("transcriber command canceled")
+		}
+		if cmdCtx.Err() == context.DeadlineExceeded {
+			return nil, fmt.Errorf("transcriber command timed out after %v", WhisperTimeout)
+		}
+		return nil, fmt.Errorf("transcriber command failed: %w\nstderr: %s", err, stderr.String())
+	}
+
+	return &whisperOutput{text: string(output)}, nil
+}
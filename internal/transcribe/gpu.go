@@ -0,0 +1,134 @@
+package transcribe
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// GPUProbeTimeout bounds how long the whisper banner probe may run
+const GPUProbeTimeout = 2 * time.Minute
+
+// GPUInfo describes the acceleration backend reported by a whisper.cpp build
+type GPUInfo struct {
+	Backend string   // Active GPU backend (e.g. "CUDA", "Metal"); empty when running on CPU
+	Details []string // Banner lines mentioning GPU devices or backends
+}
+
+var (
+	// initLinePattern matches log lines from model and backend initialization,
+	// leaving out usage text and transcription output
+	initLinePattern   = regexp.MustCompile(`^(whisper_|ggml_|load_backend|register_backend|system_info)`)
+	backendPattern    = regexp.MustCompile(`(?i)using (\w+) backend`)
+	gpuMarkerPattern  = regexp.MustCompile(`(?i)cuda|metal|vulkan|coreml|openvino|sycl|hip|gpu`)
+	gpuEnabledPattern = regexp.MustCompile(`\b(CUDA|METAL|VULKAN|COREML|SYCL) = 1\b`)
+)
+
+// ProbeGPU loads the configured model in whisper.cpp and parses its startup
+// log to determine whether a GPU backend is active. whisper has no dedicated
+// device-listing command, so it transcribes a short silent clip; a missing
+// input would be rejected before the model and backend are initialized.
+func ProbeGPU(ctx context.Context, opts Options) (GPUInfo, error) {
+	if err := EnsureModel(opts.ModelDir, opts.ModelSize); err != nil {
+		return GPUInfo{}, err
+	}
+
+	whisperCLI, err := findWhisperCLI()
+	if err != nil {
+		return GPUInfo{}, err
+	}
+
+	silence, err := writeSilentWAV(time.Second)
+	if err != nil {
+		return GPUInfo{}, err
+	}
+	defer os.Remove(silence)
+
+	ctx, cancel := context.WithTimeout(ctx, GPUProbeTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, whisperCLI,
+		"-m", ModelPath(opts.ModelDir, opts.ModelSize),
+		"-f", silence,
+	)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return GPUInfo{}, fmt.Errorf("whisper GPU probe timed out after %v", GPUProbeTimeout)
+		}
+		// The backend may have initialized before a later failure; report
+		// what the log shows if it got that far
+		if _, ok := err.(*exec.ExitError); !ok {
+			return GPUInfo{}, fmt.Errorf("failed to run whisper: %w", err)
+		}
+	}
+
+	return parseGPUBanner(string(output)), nil
+}
+
+// writeSilentWAV writes a temp WAV file of silence in the format whisper
+// expects and returns its path
+func writeSilentWAV(length time.Duration) (string, error) {
+	f, err := os.CreateTemp("", tempAudioPrefix+"gpu-probe-*.wav")
+	if err != nil {
+		return "", fmt.Errorf("failed to create probe audio: %w", err)
+	}
+
+	const bitsPerSample = 16
+	blockAlign := WhisperChannels * bitsPerSample / 8
+	dataSize := int(length.Seconds()*WhisperSampleRate) * blockAlign
+	header := []any{
+		[4]byte{'R', 'I', 'F', 'F'}, uint32(36 + dataSize), [4]byte{'W', 'A', 'V', 'E'},
+		[4]byte{'f', 'm', 't', ' '}, uint32(16), uint16(1), uint16(WhisperChannels),
+		uint32(WhisperSampleRate), uint32(WhisperSampleRate * blockAlign), uint16(blockAlign), uint16(bitsPerSample),
+		[4]byte{'d', 'a', 't', 'a'}, uint32(dataSize),
+	}
+	for _, field := range header {
+		if err = binary.Write(f, binary.LittleEndian, field); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		_, err = f.Write(make([]byte, dataSize))
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", fmt.Errorf("failed to write probe audio: %w", err)
+	}
+	return f.Name(), nil
+}
+
+// parseGPUBanner extracts GPU backend information from whisper's
+// initialization log lines
+func parseGPUBanner(banner string) GPUInfo {
+	var info GPUInfo
+	for _, line := range strings.Split(banner, "\n") {
+		line = strings.TrimSpace(line)
+		if !initLinePattern.MatchString(line) || !gpuMarkerPattern.MatchString(line) {
+			continue
+		}
+		info.Details = append(info.Details, line)
+
+		if info.Backend != "" {
+			continue
+		}
+		if m := backendPattern.FindStringSubmatch(line); m != nil && !strings.EqualFold(m[1], "cpu") {
+			info.Backend = m[1]
+		} else if m := gpuEnabledPattern.FindStringSubmatch(line); m != nil {
+			info.Backend = m[1]
+		} else if strings.HasPrefix(line, "ggml_cuda_init: found") && !strings.Contains(line, "found 0") {
+			info.Backend = "CUDA"
+		} else if strings.HasPrefix(line, "ggml_metal_init: found device") {
+			info.Backend = "Metal"
+		}
+	}
+	return info
+}
@@ -0,0 +1,129 @@
+package transcribe
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// commonHallucinations are whisper artifacts seen regardless of language,
+// typically emitted for silence or music
+var commonHallucinations = []string{
+	"[BLANK_AUDIO]", "[Music]", "[MUSIC]", "(music)", "[Applause]", "[Laughter]", "[Silence]", "♪",
+}
+
+// DefaultHallucinations lists phrases whisper tends to invent during quiet
+// sections, by language code. They come from the subtitle credits and
+// sign-offs in its training data.
+var DefaultHallucinations = map[string][]string{
+	"en": {"Thank you.", "Thanks for watching!", "Thank you for watching.", "Please subscribe.", "Subtitles by the Amara.org community", "you"},
+	"es": {"¡Gracias por ver!", "Gracias.", "Subtítulos realizados por la comunidad de Amara.org", "[Música]", "¡Suscríbete!"},
+	"fr": {"Merci.", "Merci d'avoir regardé !", "Sous-titrage Société Radio-Canada", "Sous-titres réalisés par la communauté d'Amara.org", "[Musique]"},
+	"de": {"Vielen Dank.", "Danke fürs Zuschauen!", "Untertitel im Auftrag des ZDF, 2017", "Untertitel der Amara.org-Community", "[Musik]"},
+	"it": {"Grazie.", "Grazie per la visione!", "Sottotitoli creati dalla comunità Amara.org", "[Musica]"},
+	"pt": {"Obrigado.", "Obrigado por assistir!", "Legendas pela comunidade Amara.org", "[Música]"},
+	"nl": {"Bedankt voor het kijken!", "Ondertiteld door de Amara.org gemeenschap", "[Muziek]"},
+	"ja": {"ご視聴ありがとうございました", "ご視聴ありがとうございました。", "[音楽]"},
+	"zh": {"谢谢观看", "请不吝点赞 订阅 转发 打赏支持明镜与点点栏目", "字幕由Amara.org社区提供"},
+	"ko": {"시청해 주셔서 감사합니다.", "구독과 좋아요 부탁드립니다.", "[음악]"},
+	"ru": {"Спасибо за просмотр!", "Продолжение следует...", "Субтитры сделал DimaTorzok", "[Музыка]"},
+}
+
+// HallucinationPhrases returns the artifact phrases for a language: the
+// common ones plus the language's defaults. "auto" or an unknown language
+// gets every language's phrases, since the spoken language isn't known.
+func HallucinationPhrases(language string) []string {
+	phrases := append([]string{}, commonHallucinations...)
+	if langPhrases, ok := DefaultHallucinations[language]; ok {
+		return append(phrases, langPhrases...)
+	}
+	for _, langPhrases := range DefaultHallucinations {
+		phrases = append(phrases, langPhrases...)
+	}
+	return phrases
+}
+
+// LoadHallucinationList reads extra artifact phrases from a file, one per
+// line. Blank lines and lines starting with # are ignored.
+func LoadHallucinationList(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read hallucination list: %w", err)
+	}
+	defer f.Close()
+
+	var phrases []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		phrases = append(phrases, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read hallucination list: %w", err)
+	}
+	return phrases, nil
+}
+
+// IsHallucination reports whether text consists only of artifact phrases,
+// possibly repeated (e.g. "Thank you. Thank you."). Matching ignores case,
+// punctuation, and spacing, and only whole lines are considered so real
+// sentences that contain a phrase are kept.
+func IsHallucination(text string, phrases []string) bool {
+	rest := normalizeArtifact(text)
+	if rest == "" {
+		return false
+	}
+	normalized := make([]string, 0, len(phrases))
+	for _, phrase := range phrases {
+		if p := normalizeArtifact(phrase); p != "" {
+			normalized = append(normalized, p)
+		}
+	}
+	return onlyArtifacts(rest, normalized)
+}
+
+// onlyArtifacts reports whether normalized text splits entirely into the
+// given phrases. Every matching phrase is tried, so a short phrase ("thank
+// you") can't block a longer one ("thank you for watching").
+func onlyArtifacts(text string, phrases []string) bool {
+	if text == "" {
+		return true
+	}
+	for _, p := range phrases {
+		if text == p || strings.HasPrefix(text, p+" ") {
+			if onlyArtifacts(strings.TrimSpace(text[len(p):]), phrases) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// FilterHallucinations removes transcript lines that are only artifact
+// phrases and returns the cleaned transcript with the number of lines removed
+func FilterHallucinations(transcript string, phrases []string) (string, int) {
+	var kept []string
+	removed := 0
+	for _, line := range strings.Split(transcript, "\n") {
+		if IsHallucination(line, phrases) {
+			removed++
+			continue
+		}
+		kept = append(kept, line)
+	}
+	return strings.Join(kept, "\n"), removed
+}
+
+// normalizeArtifact lowercases text and reduces punctuation and spacing to
+// single spaces, keeping bracketed tags like [music] recognizable
+func normalizeArtifact(text string) string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune("[]()♪", r)
+	})
+	return strings.Join(fields, " ")
+}
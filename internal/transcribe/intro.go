@@ -0,0 +1,27 @@
+package transcribe
+
+import (
+	"regexp"
+	"strings"
+)
+
+// sentenceEnd matches the end of a sentence: terminal punctuation, any
+// closing quotes or brackets, then whitespace
+var sentenceEnd = regexp.MustCompile(`[.!?]+["')\]]*\s+`)
+
+// SkipSentences drops the first n sentences of a transcript. It is the
+// text-based alternative to Options.SkipIntro for intros of varying length;
+// trimming audio is preferred when the intro length is fixed, since it also
+// saves transcription time. Returns "" if the transcript has n or fewer
+// sentences.
+func SkipSentences(transcript string, n int) string {
+	text := strings.TrimSpace(transcript)
+	for i := 0; i < n; i++ {
+		loc := sentenceEnd.FindStringIndex(text)
+		if loc == nil {
+			return ""
+		}
+		text = text[loc[1]:]
+	}
+	return strings.TrimSpace(text)
+}
@@ -0,0 +1,62 @@
+package transcribe
+
+import (
+	"context"
+	"runtime"
+)
+
+// Limits caps how many ffmpeg and transcriber processes run at once across
+// concurrent transcriptions sharing it. ffmpeg extraction is mostly I/O-bound
+// while whisper is CPU- and memory-bound, so they are limited separately.
+// A nil *Limits imposes no caps.
+type Limits struct {
+	ffmpeg  chan struct{}
+	whisper chan struct{}
+}
+
+// NewLimits returns limits allowing the given numbers of concurrent ffmpeg
+// and transcriber processes (at least one each)
+func NewLimits(ffmpegJobs, whisperJobs int) *Limits {
+	return &Limits{
+		ffmpeg:  make(chan struct{}, max(ffmpegJobs, 1)),
+		whisper: make(chan struct{}, max(whisperJobs, 1)),
+	}
+}
+
+// DefaultFFmpegJobs is the default ffmpeg concurrency: one per core, up to 4,
+// beyond which extraction is limited by disk rather than CPU
+func DefaultFFmpegJobs() int {
+	return min(runtime.NumCPU(), 4)
+}
+
+// DefaultWhisperJobs is the default transcriber concurrency. whisper.cpp
+// uses 4 threads per process, so this allows one process per 4 cores.
+func DefaultWhisperJobs() int {
+	return max(runtime.NumCPU()/4, 1)
+}
+
+// ffmpegSlot waits for an ffmpeg slot and returns a function releasing it
+func (l *Limits) ffmpegSlot(ctx context.Context) (func(), error) {
+	if l == nil {
+		return func() {}, nil
+	}
+	return acquire(ctx, l.ffmpeg)
+}
+
+// whisperSlot waits for a transcriber slot and returns a function releasing it
+func (l *Limits) whisperSlot(ctx context.Context) (func(), error) {
+	if l == nil {
+		return func() {}, nil
+	}
+	return acquire(ctx, l.whisper)
+}
+
+// acquire takes a slot from a semaphore channel, giving up if ctx ends first
+func acquire(ctx context.Context, slots chan struct{}) (func(), error) {
+	select {
+	case slots <- struct{}{}:
+		return func() { <-slots }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,153 @@
+package transcribe
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Stages reported in the progress file
+const (
+	ProgressExtracting   = "extracting"
+	ProgressTranscribing = "transcribing"
+	ProgressDone         = "done"
+	ProgressFailed       = "failed"
+)
+
+// Progress is the content of Options.ProgressFile. Percent is -1 when the
+// transcriber doesn't report it (e.g. --transcriber-cmd).
+type Progress struct {
+	Stage          string    `json:"stage"`
+	Percent        int       `json:"percent"`
+	ElapsedSeconds float64   `json:"elapsed_seconds"`
+	Error          string    `json:"error,omitempty"`
+	Updated        time.Time `json:"updated"`
+}
+
+// progressFile writes Progress snapshots to a file for other processes to
+// poll. Each update atomically replaces the file, so readers never see a
+// partial write. A nil *progressFile ignores updates.
+type progressFile struct {
+	path  string
+	start time.Time
+
+	mu      sync.Mutex
+	stage   string
+	percent int
+}
+
+// newProgressFile returns a writer for path, or nil when path is empty
+func newProgressFile(path string) *progressFile {
+	if path == "" {
+		return nil
+	}
+	return &progressFile{path: path, start: time.Now(), percent: -1}
+}
+
+// update records the current stage and percent. Write failures only warn:
+// progress reporting must never fail a transcription.
+func (p *progressFile) update(stage string, percent int, stageErr error) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// Ignore repeats so a chatty transcriber doesn't rewrite the file constantly
+	if stage == p.stage && percent == p.percent {
+		return
+	}
+	p.stage, p.percent = stage, percent
+
+	progress := Progress{
+		Stage:          stage,
+		Percent:        percent,
+		ElapsedSeconds: time.Since(p.start).Round(100 * time.Millisecond).Seconds(),
+		Updated:        time.Now().UTC(),
+	}
+	if stageErr != nil {
+		progress.Error = stageErr.Error()
+	}
+	if err := writeProgress(p.path, progress); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to write progress file: %v\n", err)
+	}
+}
+
+// finish marks the run done or failed. A successful run's file is removed
+// unless keep is set; a failed run's file is kept so pollers see the error.
+func (p *progressFile) finish(err error, keep bool) {
+	if p == nil {
+		return
+	}
+	if err != nil {
+		p.update(ProgressFailed, p.percent, err)
+		return
+	}
+	if !keep {
+		os.Remove(p.path)
+		return
+	}
+	p.update(ProgressDone, 100, nil)
+}
+
+// writeProgress atomically replaces path with the JSON encoding of progress
+func writeProgress(path string, progress Progress) error {
+	data, err := json.Marshal(progress)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
+
+// whisperProgressPattern matches whisper.cpp's --print-progress lines, e.g.
+// "whisper_print_progress_callback: progress =  45%"
+var whisperProgressPattern = regexp.MustCompile(`progress\s*=\s*(\d+)%`)
+
+// progressScanner collects a command's output while passing each progress
+// percentage it reports to onPercent
+type progressScanner struct {
+	output    bytes.Buffer
+	pending   []byte
+	onPercent func(int)
+}
+
+func (s *progressScanner) Write(data []byte) (int, error) {
+	s.output.Write(data)
+	s.pending = append(s.pending, data...)
+	for {
+		i := bytes.IndexByte(s.pending, '\n')
+		if i < 0 {
+			break
+		}
+		if m := whisperProgressPattern.FindSubmatch(s.pending[:i]); m != nil {
+			if percent, err := strconv.Atoi(string(m[1])); err == nil {
+				s.onPercent(percent)
+			}
+		}
+		s.pending = s.pending[i+1:]
+	}
+	return len(data), nil
+}
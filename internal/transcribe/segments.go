@@ -0,0 +1,171 @@
+package transcribe
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// SpeakerTurnMarker is the token whisper.cpp emits at tinydiarize speaker changes
+const SpeakerTurnMarker = "[SPEAKER_TURN]"
+
+// Segment is a timed span of transcript text
+type Segment struct {
+	Start   int64  `json:"start_ms"` // Offset from the start of the video in milliseconds
+	End     int64  `json:"end_ms"`
+	Speaker string `json:"speaker"` // Empty when diarization is off
+	Text    string `json:"text"`
+
+	turnNext bool // Speaker changes after this segment
+}
+
+// TranscribeSegments transcribes a video and returns whisper's timed segments.
+// When opts.Diarize is set, each segment carries a speaker label derived from
+// the speaker turn markers (see AssignSpeakers).
+func TranscribeSegments(ctx context.Context, videoPath string, opts Options) ([]Segment, error) {
+	if opts.TranscriberCmd != "" {
+		return nil, fmt.Errorf("timed segments are not available with an external transcriber command")
+	}
+
+	out, err := transcribeVideo(ctx, videoPath, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	segments := out.segments
+	if len(segments) == 0 {
+		return nil, fmt.Errorf("no speech detected in video")
+	}
+
+	if opts.Diarize {
+		AssignSpeakers(segments)
+	}
+
+	// --range and --skip-intro cut the audio before whisper sees it; report
+	// times in the source video rather than the clip
+	ShiftSegments(segments, sourceOffset(opts))
+	return segments, nil
+}
+
+// sourceOffset returns where the extracted audio starts in the video. Time
+// removed by TrimSilence isn't included since its length isn't known.
+func sourceOffset(opts Options) time.Duration {
+	if opts.RangeEnd > 0 {
+		return opts.RangeStart
+	}
+	return opts.SkipIntro
+}
+
+// ShiftSegments moves every segment later by offset
+func ShiftSegments(segments []Segment, offset time.Duration) {
+	for i := range segments {
+		segments[i].Start += offset.Milliseconds()
+		segments[i].End += offset.Milliseconds()
+	}
+}
+
+// parseWhisperJSON decodes the segments from whisper.cpp's -oj output
+func parseWhisperJSON(data []byte) ([]Segment, error) {
+	var doc struct {
+		Transcription []struct {
+			Offsets struct {
+				From int64 `json:"from"`
+				To   int64 `json:"to"`
+			} `json:"offsets"`
+			Text            string `json:"text"`
+			SpeakerTurnNext bool   `json:"speaker_turn_next"`
+		} `json:"transcription"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse whisper JSON output: %w", err)
+	}
+
+	segments := make([]Segment, 0, len(doc.Transcription))
+	for _, t := range doc.Transcription {
+		text := strings.TrimSpace(t.Text)
+		turnNext := t.SpeakerTurnNext
+		if strings.Contains(text, SpeakerTurnMarker) {
+			turnNext = true
+			text = strings.TrimSpace(strings.ReplaceAll(text, SpeakerTurnMarker, ""))
+		}
+		if text == "" {
+			continue
+		}
+		segments = append(segments, Segment{
+			Start:    t.Offsets.From,
+			End:      t.Offsets.To,
+			Text:     text,
+			turnNext: turnNext,
+		})
+	}
+	return segments, nil
+}
+
+// AssignSpeakers labels segments with speakers based on speaker turns.
+// tinydiarize only detects that the speaker changed, not who is speaking, so
+// labels alternate between SPEAKER_1 and SPEAKER_2 at each turn, which fits
+// the two-person recordings it is intended for.
+func AssignSpeakers(segments []Segment) {
+	speaker := 1
+	for i := range segments {
+		segments[i].Speaker = fmt.Sprintf("SPEAKER_%d", speaker)
+		if segments[i].turnNext {
+			speaker = 3 - speaker
+		}
+	}
+}
+
+// MergeSpeakerTurns combines consecutive segments from the same speaker into
+// a single block spanning their combined time range. Segments without a
+// speaker are left as they are.
+func MergeSpeakerTurns(segments []Segment) []Segment {
+	var merged []Segment
+	for _, seg := range segments {
+		if n := len(merged); n > 0 && seg.Speaker != "" && merged[n-1].Speaker == seg.Speaker {
+			last := &merged[n-1]
+			last.End = seg.End
+			last.Text += " " + seg.Text
+			last.turnNext = seg.turnNext
+			continue
+		}
+		merged = append(merged, seg)
+	}
+	return merged
+}
+
+// sentenceEndings are the characters that end a sentence in a segment
+const sentenceEndings = ".!?…"
+
+// MergeShortGaps joins segments that whisper split mid-sentence: a segment is
+// merged into the one before it when it starts less than gap after that one
+// ends, the earlier segment doesn't end a sentence, and both have the same
+// speaker. A gap of 0 or less returns the segments unchanged.
+func MergeShortGaps(segments []Segment, gap time.Duration) []Segment {
+	if gap <= 0 {
+		return segments
+	}
+	var merged []Segment
+	for _, seg := range segments {
+		if n := len(merged); n > 0 {
+			last := &merged[n-1]
+			if !endsSentence(last.Text) && seg.Speaker == last.Speaker && seg.Start-last.End < gap.Milliseconds() {
+				last.End = seg.End
+				last.Text += " " + seg.Text
+				last.turnNext = seg.turnNext
+				continue
+			}
+		}
+		merged = append(merged, seg)
+	}
+	return merged
+}
+
+// endsSentence reports whether text ends with sentence punctuation, ignoring
+// closing quotes and brackets
+func endsSentence(text string) bool {
+	r, size := utf8.DecodeLastRuneInString(strings.TrimRight(text, `"')]`))
+	return size > 0 && strings.ContainsRune(sentenceEndings, r)
+}
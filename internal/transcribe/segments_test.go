@@ -0,0 +1,163 @@
+package transcribe
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMergeSpeakerTurns(t *testing.T) {
+	tests := []struct {
+		name     string
+		segments []Segment
+		want     []Segment
+	}{
+		{
+			name: "merges adjacent same-speaker segments",
+			segments: []Segment{
+				{Start: 0, End: 1000, Speaker: "SPEAKER_1", Text: "Hello there."},
+				{Start: 1000, End: 2500, Speaker: "SPEAKER_1", Text: "How are you?"},
+				{Start: 2600, End: 4000, Speaker: "SPEAKER_2", Text: "Fine, thanks."},
+				{Start: 4000, End: 5000, Speaker: "SPEAKER_2", Text: "And you?"},
+				{Start: 5200, End: 6000, Speaker: "SPEAKER_1", Text: "Good."},
+			},
+			want: []Segment{
+				{Start: 0, End: 2500, Speaker: "SPEAKER_1", Text: "Hello there. How are you?"},
+				{Start: 2600, End: 5000, Speaker: "SPEAKER_2", Text: "Fine, thanks. And you?"},
+				{Start: 5200, End: 6000, Speaker: "SPEAKER_1", Text: "Good."},
+			},
+		},
+		{
+			name: "keeps alternating speakers apart",
+			segments: []Segment{
+				{Start: 0, End: 1000, Speaker: "SPEAKER_1", Text: "Ready?"},
+				{Start: 1000, End: 2000, Speaker: "SPEAKER_2", Text: "Yes."},
+			},
+			want: []Segment{
+				{Start: 0, End: 1000, Speaker: "SPEAKER_1", Text: "Ready?"},
+				{Start: 1000, End: 2000, Speaker: "SPEAKER_2", Text: "Yes."},
+			},
+		},
+		{
+			name: "leaves segments without a speaker label alone",
+			segments: []Segment{
+				{Start: 0, End: 1000, Text: "First"},
+				{Start: 1000, End: 2000, Text: "second"},
+				{Start: 2000, End: 3000, Speaker: "SPEAKER_1", Text: "Third."},
+			},
+			want: []Segment{
+				{Start: 0, End: 1000, Text: "First"},
+				{Start: 1000, End: 2000, Text: "second"},
+				{Start: 2000, End: 3000, Speaker: "SPEAKER_1", Text: "Third."},
+			},
+		},
+		{
+			name: "an unlabeled segment separates the same speaker",
+			segments: []Segment{
+				{Start: 0, End: 1000, Speaker: "SPEAKER_1", Text: "One."},
+				{Start: 1000, End: 2000, Text: "Two."},
+				{Start: 2000, End: 3000, Speaker: "SPEAKER_1", Text: "Three."},
+			},
+			want: []Segment{
+				{Start: 0, End: 1000, Speaker: "SPEAKER_1", Text: "One."},
+				{Start: 1000, End: 2000, Text: "Two."},
+				{Start: 2000, End: 3000, Speaker: "SPEAKER_1", Text: "Three."},
+			},
+		},
+		{
+			name:     "empty input",
+			segments: nil,
+			want:     nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MergeSpeakerTurns(tt.segments); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MergeSpeakerTurns() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeShortGaps(t *testing.T) {
+	split := []Segment{
+		{Start: 0, End: 1000, Text: "We start by"},
+		{Start: 1300, End: 2000, Text: "installing ffmpeg."},
+	}
+	joined := []Segment{{Start: 0, End: 2000, Text: "We start by installing ffmpeg."}}
+
+	tests := []struct {
+		name     string
+		segments []Segment
+		gap      time.Duration
+		want     []Segment
+	}{
+		{
+			name:     "merges a gap below the threshold",
+			segments: split,
+			gap:      301 * time.Millisecond,
+			want:     joined,
+		},
+		{
+			name:     "keeps a gap equal to the threshold",
+			segments: split,
+			gap:      300 * time.Millisecond,
+			want:     split,
+		},
+		{
+			name:     "keeps a gap above the threshold",
+			segments: split,
+			gap:      299 * time.Millisecond,
+			want:     split,
+		},
+		{
+			name:     "zero gap disables merging",
+			segments: split,
+			gap:      0,
+			want:     split,
+		},
+		{
+			name: "keeps segments that end a sentence",
+			segments: []Segment{
+				{Start: 0, End: 1000, Text: "That's it."},
+				{Start: 1000, End: 2000, Text: "Next up"},
+			},
+			gap: time.Second,
+			want: []Segment{
+				{Start: 0, End: 1000, Text: "That's it."},
+				{Start: 1000, End: 2000, Text: "Next up"},
+			},
+		},
+		{
+			name: "keeps different speakers apart",
+			segments: []Segment{
+				{Start: 0, End: 1000, Speaker: "SPEAKER_1", Text: "So the"},
+				{Start: 1000, End: 2000, Speaker: "SPEAKER_2", Text: "right."},
+			},
+			gap: time.Second,
+			want: []Segment{
+				{Start: 0, End: 1000, Speaker: "SPEAKER_1", Text: "So the"},
+				{Start: 1000, End: 2000, Speaker: "SPEAKER_2", Text: "right."},
+			},
+		},
+		{
+			name:     "empty input",
+			segments: nil,
+			gap:      time.Second,
+			want:     nil,
+		},
+		{
+			name:     "single segment",
+			segments: []Segment{{Start: 0, End: 1000, Text: "Only one"}},
+			gap:      time.Second,
+			want:     []Segment{{Start: 0, End: 1000, Text: "Only one"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MergeShortGaps(tt.segments, tt.gap); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MergeShortGaps() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,10 +2,13 @@ package transcribe
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -13,27 +16,233 @@ import (
 // ValidModels is the list of valid whisper model sizes
 var ValidModels = map[string]bool{
 	"tiny": true, "base": true, "small": true, "medium": true, "large": true,
+	"small.en-tdrz": true, // tinydiarize model, required for --diarize
 }
 
 // Default timeouts for external commands
 const (
-	FFmpegTimeout  = 30 * time.Minute  // Audio extraction timeout
-	WhisperTimeout = 60 * time.Minute  // Transcription timeout (can be slow for large files)
+	FFmpegTimeout  = 30 * time.Minute        // Audio extraction timeout
+	FFprobeTimeout = 1 * time.Minute         // Stream probing timeout
+	WhisperTimeout = 60 * time.Minute        // Transcription timeout (can be slow for large files)
 	MaxVideoSize   = 10 * 1024 * 1024 * 1024 // 10GB max video size
 )
 
-// ModelPath returns the expected path for a whisper model
-func ModelPath(modelSize string) string {
+// ModelDirEnv is the environment variable that overrides the model directory
+const ModelDirEnv = "WHISPER_MODEL_DIR"
+
+// Options configures a transcription run
+type Options struct {
+	ModelSize  string // Whisper model size (see ValidModels)
+	ModelDir   string // Directory holding ggml model files (default: DefaultModelDir)
+	AudioTrack int    // Audio stream to extract, counted among audio streams only
+	Diarize    bool   // Mark speaker turns (requires a tinydiarize model)
+	Language   string // Spoken language code passed to whisper (e.g. "en", "auto"); empty uses whisper's default
+	SampleRate int    // Extracted audio sample rate in Hz (default: WhisperSampleRate)
+	Channels   int    // Extracted audio channel count (default: WhisperChannels)
+
+	// TranscriberCmd replaces whisper.cpp with an external command that prints
+	// the transcript to stdout. TranscriberAudioPlaceholder in the command is
+	// replaced by the extracted audio path (appended if absent).
+	TranscriberCmd string
+
+	// AutoChunkOnTimeout retries a whisper run that hits WhisperTimeout by
+	// transcribing the audio in ChunkDuration pieces
+	AutoChunkOnTimeout bool
+	ChunkDuration      time.Duration // Default: DefaultChunkDuration
+
+	// Leading/trailing silence trimming (internal pauses are kept)
+	TrimSilence      bool
+	SilenceThreshold string  // Level below which audio counts as silence (default: DefaultSilenceThreshold)
+	SilencePad       float64 // Seconds of silence kept next to speech (default: DefaultSilencePad)
+
+	SampleDuration time.Duration // Transcribe only this much audio from the start; 0 for all
+	SkipIntro      time.Duration // Drop this much audio from the start (e.g. channel boilerplate); 0 keeps all
+
+	// RangeStart and RangeEnd limit transcription to a window of the video.
+	// A zero RangeEnd disables the range.
+	RangeStart time.Duration
+	RangeEnd   time.Duration
+
+	// ProgressFile, if set, is atomically rewritten with a JSON Progress
+	// snapshot as transcription runs, for GUIs to poll. It is removed when
+	// transcription succeeds unless KeepProgressFile is set.
+	ProgressFile     string
+	KeepProgressFile bool
+
+	// Limits, if set, caps concurrent ffmpeg and transcriber processes
+	// across every transcription sharing it
+	Limits *Limits
+
+	onProgress func(percent int) // Receives whisper's progress reports
+}
+
+// Name prefixes of the temp files and directories created during a run.
+// They are removed when a run finishes; TempPrefixes lets a cleanup sweep
+// find leftovers from runs that crashed.
+const (
+	tempAudioPrefix      = "video-journal-audio-"
+	tempTranscriptPrefix = "video-journal-transcript-"
+	tempChunksPrefix     = "video-journal-chunks-"
+)
+
+// TempPrefixes lists the name prefixes of this package's temp files
+var TempPrefixes = []string{tempAudioPrefix, tempTranscriptPrefix, tempChunksPrefix}
+
+// Audio format whisper.cpp expects. Other values are only useful with forks
+// that accept them.
+const (
+	WhisperSampleRate = 16000
+	WhisperChannels   = 1
+)
+
+// Fingerprint summarizes the settings that affect the transcript text, for
+// use in cache keys
+func (o Options) Fingerprint() string {
+	return fmt.Sprintf("model=%s lang=%s track=%d diarize=%t trim=%t threshold=%s pad=%g rate=%d channels=%d cmd=%q sample=%v skip=%v range=%v-%v",
+		o.ModelSize, o.Language, o.AudioTrack, o.Diarize, o.TrimSilence, o.SilenceThreshold, o.SilencePad, o.SampleRate, o.Channels, o.TranscriberCmd, o.SampleDuration, o.SkipIntro, o.RangeStart, o.RangeEnd)
+}
+
+// Defaults for TrimSilence. -50dB treats room tone as silence while keeping
+// quiet speech; the pad avoids clipping the first and last syllables.
+const (
+	DefaultSilenceThreshold = "-50dB"
+	DefaultSilencePad       = 0.2
+)
+
+// trimSilenceFilter builds an ffmpeg filter that removes silence only at the
+// start and end of the audio. silenceremove trims the start; reversing the
+// audio lets the same filter trim the end.
+func trimSilenceFilter(threshold string, pad float64) string {
+	if threshold == "" {
+		threshold = DefaultSilenceThreshold
+	}
+	trim := fmt.Sprintf("silenceremove=start_periods=1:start_threshold=%s:start_silence=%g", threshold, pad)
+	return trim + ",areverse," + trim + ",areverse"
+}
+
+// AudioTrack describes an audio stream in a video file
+type AudioTrack struct {
+	Index    int // Position among the file's audio streams (for -map 0:a:N)
+	Codec    string
+	Channels int
+	Language string
+	Title    string
+}
+
+// ListAudioTracks returns the audio streams of a video file using ffprobe
+func ListAudioTracks(ctx context.Context, videoPath string) ([]AudioTrack, error) {
+	ctx, cancel := context.WithTimeout(ctx, FFprobeTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "ffprobe", "-v", "error",
+		"-select_streams", "a",
+		"-show_entries", "stream=codec_name,channels:stream_tags=language,title",
+		"-of", "json",
+		videoPath,
+	)
+	output, err := cmd.Output()
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, fmt.Errorf("ffprobe timed out after %v", FFprobeTimeout)
+		}
+		return nil, fmt.Errorf("ffprobe failed: %w\nMake sure ffmpeg is installed", err)
+	}
+
+	var probe struct {
+		Streams []struct {
+			CodecName string `json:"codec_name"`
+			Channels  int    `json:"channels"`
+			Tags      struct {
+				Language string `json:"language"`
+				Title    string `json:"title"`
+			} `json:"tags"`
+		} `json:"streams"`
+	}
+	if err := json.Unmarshal(output, &probe); err != nil {
+		return nil, fmt.Errorf("failed to parse ffprobe output: %w", err)
+	}
+
+	tracks := make([]AudioTrack, 0, len(probe.Streams))
+	for i, st := range probe.Streams {
+		tracks = append(tracks, AudioTrack{
+			Index:    i,
+			Codec:    st.CodecName,
+			Channels: st.Channels,
+			Language: st.Tags.Language,
+			Title:    st.Tags.Title,
+		})
+	}
+	return tracks, nil
+}
+
+// ProbeDuration returns the length of a video using ffprobe
+func ProbeDuration(ctx context.Context, videoPath string) (time.Duration, error) {
+	ctx, cancel := context.WithTimeout(ctx, FFprobeTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "ffprobe", "-v", "error",
+		"-show_entries", "format=duration",
+		"-of", "default=noprint_wrappers=1:nokey=1",
+		videoPath,
+	)
+	output, err := cmd.Output()
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return 0, fmt.Errorf("ffprobe timed out after %v", FFprobeTimeout)
+		}
+		return 0, fmt.Errorf("ffprobe failed: %w\nMake sure ffmpeg is installed", err)
+	}
+	seconds, err := strconv.ParseFloat(strings.TrimSpace(string(output)), 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse video duration %q: %w", strings.TrimSpace(string(output)), err)
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// checkRange verifies that opts' time range lies within the video
+func checkRange(ctx context.Context, videoPath string, opts Options) error {
+	if opts.RangeEnd <= 0 {
+		return nil
+	}
+	if opts.RangeStart >= opts.RangeEnd {
+		return fmt.Errorf("range start %v must be before its end %v", opts.RangeStart, opts.RangeEnd)
+	}
+	duration, err := ProbeDuration(ctx, videoPath)
+	if err != nil {
+		return err
+	}
+	if opts.RangeEnd > duration {
+		return fmt.Errorf("range end %v is past the end of the video (%v)", opts.RangeEnd, duration.Round(time.Second))
+	}
+	return nil
+}
+
+// ffmpegSeconds formats a duration as seconds for ffmpeg time options
+func ffmpegSeconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', 3, 64)
+}
+
+// DefaultModelDir returns the default whisper model cache directory
+func DefaultModelDir() string {
 	home, _ := os.UserHomeDir()
-	return filepath.Join(home, ".cache", "whisper", fmt.Sprintf("ggml-%s.bin", modelSize))
+	return filepath.Join(home, ".cache", "whisper")
+}
+
+// ModelPath returns the expected path for a whisper model.
+// An empty modelDir uses DefaultModelDir.
+func ModelPath(modelDir, modelSize string) string {
+	if modelDir == "" {
+		modelDir = DefaultModelDir()
+	}
+	return filepath.Join(modelDir, fmt.Sprintf("ggml-%s.bin", modelSize))
 }
 
 // EnsureModel checks if the model exists and provides download instructions if not
-func EnsureModel(modelSize string) error {
-	modelPath := ModelPath(modelSize)
+func EnsureModel(modelDir, modelSize string) error {
+	modelPath := ModelPath(modelDir, modelSize)
 	if _, err := os.Stat(modelPath); os.IsNotExist(err) {
-		return fmt.Errorf("whisper model not found at %s\n\nDownload it with:\n  mkdir -p ~/.cache/whisper\n  curl -L -o %s https://huggingface.co/ggerganov/whisper.cpp/resolve/main/ggml-%s.bin",
-			modelPath, modelPath, modelSize)
+		return fmt.Errorf("whisper model not found at %s\n\nDownload it with:\n  mkdir -p %s\n  curl -L -o %s https://huggingface.co/ggerganov/whisper.cpp/resolve/main/ggml-%s.bin",
+			modelPath, filepath.Dir(modelPath), modelPath, modelSize)
 	}
 	return nil
 }
@@ -66,10 +275,19 @@ func findWhisperCLI() (string, error) {
 	return "", fmt.Errorf("whisper.cpp CLI not found\n\nInstall whisper.cpp:\n  brew install whisper-cpp\n\nOr build from source:\n  git clone https://github.com/ggerganov/whisper.cpp\n  cd whisper.cpp && make")
 }
 
-// extractAudio extracts audio from video file using ffmpeg
-func extractAudio(ctx context.Context, videoPath string) (string, func(), error) {
+// extractAudio extracts audio from video file using ffmpeg. The FFmpegTimeout
+// starts once an ffmpeg slot is free, so waiting in line doesn't count.
+func extractAudio(ctx context.Context, videoPath string, opts Options) (string, func(), error) {
+	release, err := opts.Limits.ffmpegSlot(ctx)
+	if err != nil {
+		return "", nil, fmt.Errorf("ffmpeg audio extraction canceled")
+	}
+	defer release()
+	ctx, cancel := context.WithTimeout(ctx, FFmpegTimeout)
+	defer cancel()
+
 	// Create unique temp file for audio
-	audioFile, err := os.CreateTemp("", "video-journal-audio-*.wav")
+	audioFile, err := os.CreateTemp("", tempAudioPrefix+"*.wav")
 	if err != nil {
 		return "", nil, fmt.Errorf("failed to create temp audio file: %w", err)
 	}
@@ -80,18 +298,47 @@ func extractAudio(ctx context.Context, videoPath string) (string, func(), error)
 		os.Remove(audioPath)
 	}
 
-	// Use ffmpeg to extract audio as 16kHz mono WAV (required by whisper)
-	cmd := exec.CommandContext(ctx, "ffmpeg", "-y",
+	// Use ffmpeg to extract audio as WAV, 16kHz mono unless overridden (required by whisper)
+	sampleRate := opts.SampleRate
+	if sampleRate <= 0 {
+		sampleRate = WhisperSampleRate
+	}
+	channels := opts.Channels
+	if channels <= 0 {
+		channels = WhisperChannels
+	}
+	args := []string{"-y"}
+	// Seeking before -i skips decoding the unwanted audio entirely
+	if opts.SkipIntro > 0 {
+		args = append(args, "-ss", ffmpegSeconds(opts.SkipIntro))
+	}
+	if opts.RangeEnd > 0 {
+		args = append(args, "-ss", ffmpegSeconds(opts.RangeStart), "-to", ffmpegSeconds(opts.RangeEnd))
+	}
+	args = append(args,
 		"-i", videoPath,
-		"-ar", "16000",
-		"-ac", "1",
+		"-map", fmt.Sprintf("0:a:%d", opts.AudioTrack),
+	)
+	if opts.SampleDuration > 0 {
+		args = append(args, "-t", ffmpegSeconds(opts.SampleDuration))
+	}
+	if opts.TrimSilence {
+		args = append(args, "-af", trimSilenceFilter(opts.SilenceThreshold, opts.SilencePad))
+	}
+	args = append(args,
+		"-ar", strconv.Itoa(sampleRate),
+		"-ac", strconv.Itoa(channels),
 		"-c:a", "pcm_s16le",
 		audioPath,
 	)
+	cmd := exec.CommandContext(ctx, "ffmpeg", args...)
 	cmd.Stderr = nil // Suppress ffmpeg output
 
 	if err := cmd.Run(); err != nil {
 		cleanup()
+		if ctx.Err() == context.Canceled {
+			return "", nil, fmt.Errorf("ffmpeg audio extraction canceled")
+		}
 		if ctx.Err() == context.DeadlineExceeded {
 			return "", nil, fmt.Errorf("ffmpeg audio extraction timed out after %v", FFmpegTimeout)
 		}
@@ -101,46 +348,113 @@ func extractAudio(ctx context.Context, videoPath string) (string, func(), error)
 	return audioPath, cleanup, nil
 }
 
-// TranscribeVideo transcribes a video file using whisper.cpp CLI
-func TranscribeVideo(videoPath string, modelSize string) (string, error) {
-	// Check video file exists and validate size
+// TranscribeVideo transcribes a video file using whisper.cpp CLI.
+// Canceling ctx aborts the whole operation; the ffmpeg and whisper
+// timeouts are derived from it.
+func TranscribeVideo(ctx context.Context, videoPath string, opts Options) (string, error) {
+	out, err := transcribeVideo(ctx, videoPath, opts)
+	if err != nil {
+		return "", err
+	}
+
+	result := strings.TrimSpace(out.text)
+	if result == "" {
+		return "", fmt.Errorf("no speech detected in video")
+	}
+
+	return result, nil
+}
+
+// checkVideo verifies the video file exists and is within MaxVideoSize
+func checkVideo(videoPath string) error {
 	info, err := os.Stat(videoPath)
 	if os.IsNotExist(err) {
-		return "", fmt.Errorf("video file not found: %s", videoPath)
+		return fmt.Errorf("video file not found: %s", videoPath)
 	}
 	if err != nil {
-		return "", fmt.Errorf("cannot access video file: %w", err)
+		return fmt.Errorf("cannot access video file: %w", err)
 	}
 	if info.Size() > MaxVideoSize {
-		return "", fmt.Errorf("video file too large: %d bytes (max: %d bytes)", info.Size(), MaxVideoSize)
+		return fmt.Errorf("video file too large: %d bytes (max: %d bytes)", info.Size(), MaxVideoSize)
 	}
+	return nil
+}
 
-	// Ensure model is available
-	if err := EnsureModel(modelSize); err != nil {
-		return "", err
+// whisperOutput holds the results of a transcription run
+type whisperOutput struct {
+	text     string    // Plain transcript (.txt)
+	segments []Segment // Timed segments (.json); nil for external transcribers
+}
+
+// ErrWhisperTimeout is returned when whisper.cpp exceeds WhisperTimeout
+var ErrWhisperTimeout = errors.New("whisper transcription timed out")
+
+// transcribeVideo extracts audio and runs whisper.cpp, returning its outputs
+func transcribeVideo(ctx context.Context, videoPath string, opts Options) (out *whisperOutput, err error) {
+	if err := checkVideo(videoPath); err != nil {
+		return nil, err
 	}
 
-	// Find whisper CLI
-	whisperCLI, err := findWhisperCLI()
-	if err != nil {
-		return "", err
+	if err := checkRange(ctx, videoPath, opts); err != nil {
+		return nil, err
+	}
+
+	progress := newProgressFile(opts.ProgressFile)
+	defer func() { progress.finish(err, opts.KeepProgressFile) }()
+	if progress != nil {
+		opts.onProgress = func(percent int) {
+			progress.update(ProgressTranscribing, percent, nil)
+		}
 	}
 
-	// Create context with timeout for ffmpeg
-	ffmpegCtx, ffmpegCancel := context.WithTimeout(context.Background(), FFmpegTimeout)
-	defer ffmpegCancel()
+	// Check whisper is usable before spending time on extraction
+	if opts.TranscriberCmd == "" {
+		if err := EnsureModel(opts.ModelDir, opts.ModelSize); err != nil {
+			return nil, err
+		}
+		if _, err := findWhisperCLI(); err != nil {
+			return nil, err
+		}
+	}
 
 	fmt.Println("Extracting audio from video...")
-	audioPath, audioCleanup, err := extractAudio(ffmpegCtx, videoPath)
+	progress.update(ProgressExtracting, 0, nil)
+	audioPath, audioCleanup, err := extractAudio(ctx, videoPath, opts)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	defer audioCleanup()
 
+	if opts.TranscriberCmd != "" {
+		progress.update(ProgressTranscribing, -1, nil)
+		release, err := opts.Limits.whisperSlot(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("transcription canceled")
+		}
+		defer release()
+		return runTranscriberCmd(ctx, opts.TranscriberCmd, audioPath)
+	}
+
+	progress.update(ProgressTranscribing, 0, nil)
+	out, err = runWhisper(ctx, audioPath, opts)
+	if errors.Is(err, ErrWhisperTimeout) && opts.AutoChunkOnTimeout {
+		fmt.Printf("Whisper timed out; falling back to chunked transcription (%v pieces)...\n", chunkDuration(opts))
+		return runWhisperChunked(ctx, audioPath, opts)
+	}
+	return out, err
+}
+
+// runWhisper transcribes an extracted audio file with whisper.cpp CLI
+func runWhisper(ctx context.Context, audioPath string, opts Options) (*whisperOutput, error) {
+	whisperCLI, err := findWhisperCLI()
+	if err != nil {
+		return nil, err
+	}
+
 	// Create unique temp file prefix for whisper output
-	outputFile, err := os.CreateTemp("", "video-journal-transcript-*")
+	outputFile, err := os.CreateTemp("", tempTranscriptPrefix+"*")
 	if err != nil {
-		return "", fmt.Errorf("failed to create temp output file: %w", err)
+		return nil, fmt.Errorf("failed to create temp output file: %w", err)
 	}
 	outputBase := outputFile.Name()
 	outputFile.Close()
@@ -157,40 +471,70 @@ func TranscribeVideo(videoPath string, modelSize string) (string, error) {
 	defer cleanupWhisperOutputs()
 
 	fmt.Println("Transcribing audio with whisper.cpp...")
-	modelPath := ModelPath(modelSize)
+	modelPath := ModelPath(opts.ModelDir, opts.ModelSize)
+
+	release, err := opts.Limits.whisperSlot(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("whisper transcription canceled")
+	}
+	defer release()
 
 	// Create context with timeout for whisper
-	whisperCtx, whisperCancel := context.WithTimeout(context.Background(), WhisperTimeout)
+	whisperCtx, whisperCancel := context.WithTimeout(ctx, WhisperTimeout)
 	defer whisperCancel()
 
 	// Run whisper.cpp CLI
-	cmd := exec.CommandContext(whisperCtx, whisperCLI,
+	args := []string{
 		"-m", modelPath,
 		"-f", audioPath,
 		"-otxt",
+		"-oj",
 		"-of", outputBase,
 		"--no-timestamps",
-	)
+	}
+	if opts.Language != "" {
+		args = append(args, "-l", opts.Language)
+	}
+	if opts.Diarize {
+		args = append(args, "-tdrz")
+	}
+	if opts.onProgress != nil {
+		args = append(args, "--print-progress")
+	}
+	cmd := exec.CommandContext(whisperCtx, whisperCLI, args...)
 
-	output, err := cmd.CombinedOutput()
+	var output []byte
+	if opts.onProgress != nil {
+		scanner := &progressScanner{onPercent: opts.onProgress}
+		cmd.Stdout, cmd.Stderr = scanner, scanner
+		err = cmd.Run()
+		output = scanner.output.Bytes()
+	} else {
+		output, err = cmd.CombinedOutput()
+	}
 	if err != nil {
+		if whisperCtx.Err() == context.Canceled {
+			return nil, fmt.Errorf("whisper transcription canceled")
+		}
 		if whisperCtx.Err() == context.DeadlineExceeded {
-			return "", fmt.Errorf("whisper transcription timed out after %v", WhisperTimeout)
+			return nil, fmt.Errorf("%w after %v", ErrWhisperTimeout, WhisperTimeout)
 		}
-		return "", fmt.Errorf("whisper transcription failed: %w\nOutput: %s", err, string(output))
+		return nil, fmt.Errorf("whisper transcription failed: %w\nOutput: %s", err, string(output))
 	}
 
-	// Read the transcript file
-	transcriptPath := outputBase + ".txt"
-	transcript, err := os.ReadFile(transcriptPath)
+	// Read the transcript files
+	transcript, err := os.ReadFile(outputBase + ".txt")
 	if err != nil {
-		return "", fmt.Errorf("failed to read transcript: %w", err)
+		return nil, fmt.Errorf("failed to read transcript: %w", err)
 	}
-
-	result := strings.TrimSpace(string(transcript))
-	if result == "" {
-		return "", fmt.Errorf("no speech detected in video")
+	segmentsJSON, err := os.ReadFile(outputBase + ".json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read transcript segments: %w", err)
+	}
+	segments, err := parseWhisperJSON(segmentsJSON)
+	if err != nil {
+		return nil, err
 	}
 
-	return result, nil
+	return &whisperOutput{text: string(transcript), segments: segments}, nil
 }
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/chezu/video-journal/internal/blog"
+)
+
+// keywordsPath returns where --keywords writes its sidecar: next to the post,
+// or under a fixed name in an --out-bundle directory
+func keywordsPath(cfg config) string {
+	if cfg.bundleDir != "" {
+		return filepath.Join(cfg.bundleDir, bundleKeywords)
+	}
+	return strings.TrimSuffix(cfg.outputPath, filepath.Ext(cfg.outputPath)) + ".keywords.json"
+}
+
+// keywordsArtifact extracts the post's notable terms as a JSON sidecar
+func keywordsArtifact(cfg config, blogPost string) (artifact, error) {
+	keywords := blog.ExtractKeywords(blogPost, cfg.maxKeywords)
+	if keywords == nil {
+		keywords = []blog.Keyword{} // Encode as [] rather than null
+	}
+	data, err := json.MarshalIndent(keywords, "", "  ")
+	if err != nil {
+		return artifact{}, fmt.Errorf("failed to encode keywords: %w", err)
+	}
+	return artifact{path: keywordsPath(cfg), data: append(data, '\n')}, nil
+}
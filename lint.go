@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/chezu/video-journal/internal/blog"
+	"github.com/chezu/video-journal/internal/cache"
+	"github.com/chezu/video-journal/internal/pipeline"
+)
+
+// runLintStyle implements --lint-style: it generates a draft post (reusing a
+// cached one when the transcript and settings are unchanged) and prints a
+// critique of how well the draft follows the style guide. Nothing is written
+// to the output path.
+func runLintStyle(ctx context.Context, videoPath string, cfg config) error {
+	fmt.Printf("Linting style guide %s against: %s\n", describeStyle(cfg.Blog.StylePath), videoPath)
+
+	transcript, _, err := transcribeCached(ctx, videoPath, cfg)
+	if err != nil {
+		return fmt.Errorf("transcription failed: %w", err)
+	}
+
+	draft, err := draftCached(ctx, transcript, cfg)
+	if err != nil {
+		return err
+	}
+
+	if !cfg.budget.allowsCall() {
+		return errBudgetExhausted
+	}
+	critique, err := blog.LintStyle(ctx, draft, cfg.Blog)
+	if err != nil {
+		return fmt.Errorf("style lint failed: %w", err)
+	}
+
+	fmt.Printf("\n%s\n", critique)
+	return nil
+}
+
+// draftCached returns the generated post for a transcript, caching it by the
+// transcript and the settings that shape the post so repeated lint runs only
+// pay for the critique
+func draftCached(ctx context.Context, transcript string, cfg config) (string, error) {
+	key := cache.HashText(strings.Join([]string{
+		transcript, styleHash(cfg.Blog.StylePath), cfg.Blog.Language, cfg.Blog.SystemPrompt, fmt.Sprint(cfg.Blog.Explain),
+	}, "\x00"))
+	if cfg.cache != nil {
+		draft, ok, err := cfg.cache.Get("drafts", key)
+		if err != nil {
+			return "", err
+		}
+		if ok {
+			fmt.Println("Using cached draft")
+			return draft, nil
+		}
+	}
+
+	if !cfg.budget.allowsCall() {
+		return "", errBudgetExhausted
+	}
+	draft, err := pipeline.Blog(ctx, transcript, cfg.Options)
+	if err != nil {
+		return "", fmt.Errorf("blog conversion failed: %w", err)
+	}
+	draft = strings.TrimSpace(draft)
+
+	if cfg.cache != nil {
+		if err := cfg.cache.Put("drafts", key, draft); err != nil {
+			return "", err
+		}
+	}
+	return draft, nil
+}
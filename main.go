@@ -1,13 +1,28 @@
 package main
 
 import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/chezu/video-journal/internal/blog"
+	"github.com/chezu/video-journal/internal/cache"
+	"github.com/chezu/video-journal/internal/drafts"
+	"github.com/chezu/video-journal/internal/feed"
+	"github.com/chezu/video-journal/internal/pipeline"
+	"github.com/chezu/video-journal/internal/stats"
 	"github.com/chezu/video-journal/internal/transcribe"
 )
 
@@ -17,14 +32,113 @@ var validVideoExtensions = map[string]bool{
 	".webm": true, ".m4v": true, ".wmv": true, ".flv": true,
 }
 
+// languagePattern matches whisper language codes such as en or yue, or auto
+var languagePattern = regexp.MustCompile(`^([a-z]{2,3}|auto)$`)
+
+// silenceThresholdPattern matches ffmpeg decibel levels such as -50dB
+var silenceThresholdPattern = regexp.MustCompile(`^-?\d+(\.\d+)?dB$`)
+
 func main() {
+	// Dispatch subcommands
+	if len(os.Args) > 1 {
+		var subcommand func([]string) error
+		switch os.Args[1] {
+		case "approve":
+			subcommand = runApprove
+		case "reprocess":
+			subcommand = runReprocess
+		case "clean-temp":
+			subcommand = runCleanTemp
+		case "init-style":
+			subcommand = runInitStyle
+		}
+		if subcommand != nil {
+			if err := subcommand(os.Args[2:]); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
 	// Define flags
-	modelFlag := flag.String("model", "base", "Whisper model size (tiny/base/small/medium/large)")
-	styleFlag := flag.String("style", "style_guide.md", "Path to style guide file")
+	modelFlag := flag.String("model", "base", "Whisper model size (tiny/base/small/medium/large, small.en-tdrz for --diarize)")
+	styleFlag := flag.String("style", "style_guide.md", "Path to style guide file (overrides per-video <name>.style.md)")
 	outputFlag := flag.String("output", "", "Output file path (default: auto-generated from video name)")
 	forceFlag := flag.Bool("force", false, "Overwrite output file if it exists")
+	onlyChangedFlag := flag.Bool("only-changed", false, "Skip videos whose transcript and style guide are unchanged since their post was last generated (regenerates stale posts in place)")
+	modelDirFlag := flag.String("model-dir", "", "Directory containing whisper models (default: $"+transcribe.ModelDirEnv+" or ~/.cache/whisper)")
+	downloadModelFlag := flag.Bool("download-model", false, "Download the whisper model if it is missing (resumes partial downloads)")
+	downloadRetriesFlag := flag.Int("download-retries", transcribe.DefaultDownloadRetries, "Times to retry and resume a failed --download-model, with backoff")
+	downloadTimeoutFlag := flag.Duration("download-timeout", transcribe.DefaultDownloadTimeout, "Time limit for each --download-model attempt; 0 means no limit")
+	selectAudioFlag := flag.Bool("select-audio", false, "Choose the audio track interactively when the video has several (track 0 when not on a TTY)")
+	sourceLinkFlag := flag.String("source-link", "", "URL of the source video to reference under the post title")
+	videoAnchorsFlag := flag.String("video-anchors", "", "Base URL of the hosted video; paragraphs that clearly match a transcript region get a [[m:ss]](URL#t=seconds) link")
+	maxOutputSizeFlag := flag.Int("max-output-size", blog.DefaultMaxOutputSize, "Maximum size of the generated post in bytes")
+	proofreadFlag := flag.Bool("proofread", false, "Run a light copyedit pass over the post (pass a .md file to proofread an existing post)")
+	diarizeFlag := flag.Bool("diarize", false, "Mark speaker turns with tinydiarize (requires --model small.en-tdrz)")
+	jsonFlag := flag.Bool("json", false, "Write the timed transcript as JSON instead of a blog post")
+	minSegmentGapFlag := flag.Duration("min-segment-gap", 0, "With --json, merge segments split mid-sentence by less than this gap (e.g. 300ms); 0 keeps whisper's segments")
+	trimSilenceFlag := flag.Bool("trim-silence", false, "Trim silence at the start and end of the audio (internal pauses are kept)")
+	silenceThresholdFlag := flag.String("silence-threshold", transcribe.DefaultSilenceThreshold, "Level below which audio counts as silence for --trim-silence")
+	silencePadFlag := flag.Float64("silence-pad", transcribe.DefaultSilencePad, "Seconds of silence to keep next to speech for --trim-silence")
+	sampleRateFlag := flag.Int("sample-rate", transcribe.WhisperSampleRate, "Audio sample rate in Hz for extraction (whisper expects 16000; change only for experiments)")
+	channelsFlag := flag.Int("channels", transcribe.WhisperChannels, "Audio channel count for extraction (whisper expects 1; change only for experiments)")
+	transcriberCmdFlag := flag.String("transcriber-cmd", "", "External command to transcribe instead of whisper.cpp; "+transcribe.TranscriberAudioPlaceholder+" is replaced by the audio path and the transcript is read from stdout")
+	autoChunkFlag := flag.Bool("auto-chunk-on-timeout", false, "If whisper times out, retry once by transcribing the audio in 10-minute chunks")
+	skipIntroFlag := flag.Duration("skip-intro", 0, "Drop this much audio from the start before transcribing (e.g. 15s), skipping channel boilerplate and its transcription time")
+	skipIntroSentencesFlag := flag.Int("skip-intro-sentences", 0, "Drop this many sentences from the start of the transcript; use when the intro length varies")
+	rangeFlag := flag.String("range", "", "Transcribe only this part of the video, as start-end (e.g. 1:05:00-1:20:00)")
+	progressFileFlag := flag.String("progress-file", "", "Keep this JSON file updated with transcription stage, percent, and elapsed time for other programs to poll; batch runs insert each video's name (progress.json becomes progress.<video>.json)")
+	keepProgressFileFlag := flag.Bool("keep-progress-file", false, "Leave --progress-file in place after transcription succeeds (it is always kept on failure)")
+	hallucinationFilterFlag := flag.Bool("hallucination-filter", false, "Remove transcript lines that are known whisper artifacts for --language (e.g. \"Thank you.\", [Music]) from quiet sections")
+	hallucinationListFlag := flag.String("hallucination-list", "", "File of extra artifact phrases, one per line, for --hallucination-filter (implies it)")
+	languageFlag := flag.String("language", "en", "Spoken language of the video for whisper (e.g. en, es, auto)")
+	postLanguageFlag := flag.String("post-language", "", "Language to write the post in (default: --language, or en when auto)")
+	styleDirFlag := flag.String("style-dir", "", "Directory of per-language style guides named style.<lang>.md (default: directory of --style)")
+	outBundleFlag := flag.String("out-bundle", "", "Write the post and its artifacts ("+bundlePost+", "+bundleTranscript+", "+bundleMeta+") into this directory; batch runs get a subdirectory per video")
+	cmsJSONFlag := flag.String("cms-json", "", "Also write the post as a CMS import record (title, slug, body, excerpt, tags, date, coverImage) to this file")
+	cmsTagsFlag := flag.String("cms-tags", "", "Comma-separated tags for --cms-json")
+	cmsCoverImageFlag := flag.String("cms-cover-image", "", "Cover image URL for --cms-json")
+	titlePrefixFlag := flag.String("title-prefix", "", "Text to put before the post title, e.g. \"DevLog #{n}: \"; {n} is replaced by the next number from --title-counter, in input order")
+	titleSuffixFlag := flag.String("title-suffix", "", "Text to put after the post title; {n} works as in --title-prefix")
+	titleCounterFlag := flag.String("title-counter", DefaultTitleCounter, "File recording the numbers used for {n} in --title-prefix/--title-suffix; a regenerated post keeps its number (created if missing)")
+	keywordsFlag := flag.Bool("keywords", false, "Also write the post's notable terms, for internal linking, to <output>.keywords.json")
+	maxKeywordsFlag := flag.Int("max-keywords", blog.DefaultMaxKeywords, "Maximum number of terms for --keywords")
+	stageFlag := flag.Bool("stage", false, "Write the post to "+drafts.DefaultDir+"/ for review; publish it with 'video-journal approve <name>'")
+	budgetFlag := flag.Float64("budget", 0, "Maximum Claude spend in USD across all inputs; 0 means no limit")
+	cacheDirFlag := flag.String("cache-dir", cache.DefaultDir(), "Directory for cached transcripts")
+	cacheReadableFlag := flag.Bool("cache-readable", false, "Name cached transcripts <video>-<hash>.txt and index them by source path in index.json")
+	mergeEditsFlag := flag.String("merge-edits", "", "Hand-edited transcript to use instead of transcribing; created from the fresh transcript if missing")
+	forceTranscribeFlag := flag.Bool("force-transcribe", false, "Transcribe again even if a cached or --merge-edits transcript exists")
+	noCacheFlag := flag.Bool("no-cache", false, "Always transcribe, bypassing the transcript cache")
+	feedFlag := flag.String("feed", "", "Atom feed file to add an entry to for each post (created if missing)")
+	feedBaseURLFlag := flag.String("feed-base-url", "", "Base URL for post links in --feed entries (default: relative output path)")
+	feedAuthorFlag := flag.String("feed-author", feed.DefaultAuthor, "Author name for a newly created --feed")
+	seriesDirFlag := flag.String("series-dir", "", "Directory of earlier posts in the series; the new post avoids repeating their topics")
+	explainFlag := flag.Bool("explain", false, "Annotate each section with an HTML comment citing its source in the transcript")
+	normalizeWhitespaceFlag := flag.Bool("normalize-whitespace", true, "Tidy whitespace in the post (trailing spaces, blank line runs, list indentation) outside code blocks")
+	maxHeadingDepthFlag := flag.Int("max-heading-depth", 0, "Turn headings deeper than this level (1-6) into bold paragraphs; 0 keeps all headings")
+	contextDirFlag := flag.String("context-dir", "", "Run claude in this directory so it can read project docs there while writing")
+	fileModeFlag := flag.String("file-mode", "0644", "Octal permissions for the written post and sidecar files (e.g. 0664 for a shared directory); the default is subject to umask")
+	systemPromptFlag := flag.String("system-prompt", "", "System prompt setting the model's role (e.g. \"You are an expert technical editor.\")")
+	systemPromptFileFlag := flag.String("system-prompt-file", "", "Read the system prompt from this file")
+	statsFlag := flag.String("stats", "", "CSV file to append per-post stats to (words, sections, reading time, model, duration)")
+	compareModelsFlag := flag.String("compare-models", "", "Transcribe the video with each of these comma-separated models (e.g. small,large) and compare the results instead of writing a post")
+	sampleFlag := flag.Duration("sample", 0, "Transcribe only the first part of the audio (e.g. 5m); useful with --compare-models")
+	lintStyleFlag := flag.Bool("lint-style", false, "Diagnostic: generate a draft (cached between runs) and print which style guide rules it follows or violates, without writing a post")
+	ffmpegJobsFlag := flag.Int("ffmpeg-jobs", transcribe.DefaultFFmpegJobs(), "Maximum ffmpeg processes at once in batch and watch mode (audio extraction is mostly I/O-bound)")
+	whisperJobsFlag := flag.Int("whisper-jobs", transcribe.DefaultWhisperJobs(), "Maximum whisper processes at once in batch and watch mode (each uses several cores and the model's memory)")
+	failFastFlag := flag.Bool("fail-fast", false, "Stop a batch at the first failed input instead of continuing and summarizing failures at the end")
+	watchIntervalFlag := flag.Duration("watch-interval", DefaultWatchInterval, "How often 'watch' checks the directory for new videos")
+	gpuInfoFlag := flag.Bool("gpu-info", false, "Report whether whisper.cpp uses a GPU backend (CUDA/Metal) and exit")
 	flag.Usage = func() {
-		fmt.Fprintf(os.Stderr, "Usage: video-journal [flags] <video-path>\n\n")
+		fmt.Fprintf(os.Stderr, "Usage: video-journal [flags] <video-path|dir>...\n")
+		fmt.Fprintf(os.Stderr, "       video-journal approve [flags] <name>\n")
+		fmt.Fprintf(os.Stderr, "       video-journal reprocess [flags] <post.md|dir>...\n")
+		fmt.Fprintf(os.Stderr, "       video-journal watch [flags] <dir>\n")
+		fmt.Fprintf(os.Stderr, "       video-journal clean-temp [flags]\n")
+		fmt.Fprintf(os.Stderr, "       video-journal init-style [flags]\n\n")
 		fmt.Fprintf(os.Stderr, "Convert a video file into a blog post using AI.\n\n")
 		fmt.Fprintf(os.Stderr, "Prerequisites:\n")
 		fmt.Fprintf(os.Stderr, "  - claude CLI must be installed and authenticated\n\n")
@@ -34,7 +148,21 @@ func main() {
 		fmt.Fprintf(os.Stderr, "  video-journal --model base my-video.mp4\n")
 	}
 
-	flag.Parse()
+	// watch shares the pipeline flags, so it is handled after parsing them
+	cmdArgs := os.Args[1:]
+	watchMode := len(cmdArgs) > 0 && cmdArgs[0] == "watch"
+	if watchMode {
+		cmdArgs = cmdArgs[1:]
+	}
+	flag.CommandLine.Parse(cmdArgs)
+
+	if *gpuInfoFlag {
+		if err := printGPUInfo(transcribe.Options{ModelSize: *modelFlag, ModelDir: *modelDirFlag}); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
 
 	// Check for video path argument
 	args := flag.Args()
@@ -43,53 +171,582 @@ func main() {
 		os.Exit(1)
 	}
 
-	videoPath := args[0]
+	// Validate model size using shared constant
+	if !transcribe.ValidModels[*modelFlag] {
+		fmt.Fprintf(os.Stderr, "Error: invalid model size '%s'. Use: tiny, base, small, medium, large, or small.en-tdrz\n", *modelFlag)
+		os.Exit(1)
+	}
+
+	var compareModels []string
+	if *compareModelsFlag != "" {
+		compareModels = strings.Split(*compareModelsFlag, ",")
+		for i, model := range compareModels {
+			compareModels[i] = strings.TrimSpace(model)
+			if !transcribe.ValidModels[compareModels[i]] {
+				fmt.Fprintf(os.Stderr, "Error: invalid model size '%s' in --compare-models\n", compareModels[i])
+				os.Exit(1)
+			}
+		}
+		if len(compareModels) < 2 {
+			fmt.Fprintf(os.Stderr, "Error: --compare-models needs at least two models, e.g. small,large\n")
+			os.Exit(1)
+		}
+		if len(args) > 1 || *transcriberCmdFlag != "" {
+			fmt.Fprintf(os.Stderr, "Error: --compare-models takes a single video and cannot be used with --transcriber-cmd\n")
+			os.Exit(1)
+		}
+	}
+	if *minSegmentGapFlag < 0 {
+		fmt.Fprintf(os.Stderr, "Error: --min-segment-gap must not be negative\n")
+		os.Exit(1)
+	}
+	var rangeStart, rangeEnd time.Duration
+	if *rangeFlag != "" {
+		if *sampleFlag > 0 || *skipIntroFlag > 0 {
+			fmt.Fprintf(os.Stderr, "Error: --range cannot be used with --sample or --skip-intro\n")
+			os.Exit(1)
+		}
+		var err error
+		rangeStart, rangeEnd, err = parseRange(*rangeFlag)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if *downloadRetriesFlag < 0 || *downloadTimeoutFlag < 0 {
+		fmt.Fprintf(os.Stderr, "Error: --download-retries and --download-timeout must not be negative\n")
+		os.Exit(1)
+	}
+	if *sampleFlag < 0 {
+		fmt.Fprintf(os.Stderr, "Error: --sample must not be negative\n")
+		os.Exit(1)
+	}
+	if *skipIntroFlag < 0 || *skipIntroSentencesFlag < 0 {
+		fmt.Fprintf(os.Stderr, "Error: --skip-intro and --skip-intro-sentences must not be negative\n")
+		os.Exit(1)
+	}
+
+	if *maxOutputSizeFlag <= 0 {
+		fmt.Fprintf(os.Stderr, "Error: --max-output-size must be a positive number of bytes\n")
+		os.Exit(1)
+	}
 
-	// Validate video file extension
-	ext := strings.ToLower(filepath.Ext(videoPath))
-	if !validVideoExtensions[ext] {
-		fmt.Fprintf(os.Stderr, "Error: unsupported video format '%s'. Supported formats: mp4, mov, avi, mkv, webm, m4v, wmv, flv\n", ext)
+	if *maxHeadingDepthFlag < 0 || *maxHeadingDepthFlag > 6 {
+		fmt.Fprintf(os.Stderr, "Error: --max-heading-depth must be between 0 and 6\n")
 		os.Exit(1)
 	}
 
-	// Validate model size using shared constant
-	if !transcribe.ValidModels[*modelFlag] {
-		fmt.Fprintf(os.Stderr, "Error: invalid model size '%s'. Use: tiny, base, small, medium, or large\n", *modelFlag)
+	if *contextDirFlag != "" {
+		if info, err := os.Stat(*contextDirFlag); err != nil || !info.IsDir() {
+			fmt.Fprintf(os.Stderr, "Error: context directory does not exist: %s\n", *contextDirFlag)
+			os.Exit(1)
+		}
+	}
+
+	if *seriesDirFlag != "" {
+		if info, err := os.Stat(*seriesDirFlag); err != nil || !info.IsDir() {
+			fmt.Fprintf(os.Stderr, "Error: series directory does not exist: %s\n", *seriesDirFlag)
+			os.Exit(1)
+		}
+	}
+
+	if *budgetFlag < 0 {
+		fmt.Fprintf(os.Stderr, "Error: --budget must not be negative\n")
 		os.Exit(1)
 	}
 
-	// Determine output path
-	outputPath := *outputFlag
-	if outputPath == "" {
-		baseName := filepath.Base(videoPath)
-		vidExt := filepath.Ext(baseName)
-		nameWithoutExt := strings.TrimSuffix(baseName, vidExt)
-		outputPath = nameWithoutExt + ".md"
+	systemPrompt, err := loadSystemPrompt(*systemPromptFlag, *systemPromptFileFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
 	}
 
-	// Validate output path (prevent path traversal)
-	if err := validateOutputPath(outputPath); err != nil {
+	fileMode, err := parseFileMode(*fileModeFlag)
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 
-	// Check for overwrite
-	if !*forceFlag {
-		if _, err := os.Stat(outputPath); err == nil {
-			fmt.Fprintf(os.Stderr, "Error: output file already exists: %s\nUse --force to overwrite\n", outputPath)
+	if !silenceThresholdPattern.MatchString(*silenceThresholdFlag) {
+		fmt.Fprintf(os.Stderr, "Error: invalid --silence-threshold '%s'. Use a level like -50dB\n", *silenceThresholdFlag)
+		os.Exit(1)
+	}
+	if *silencePadFlag < 0 {
+		fmt.Fprintf(os.Stderr, "Error: --silence-pad must not be negative\n")
+		os.Exit(1)
+	}
+
+	if *transcriberCmdFlag != "" && (*jsonFlag || *videoAnchorsFlag != "") {
+		fmt.Fprintf(os.Stderr, "Error: --json and --video-anchors need whisper.cpp timestamps and cannot be used with --transcriber-cmd\n")
+		os.Exit(1)
+	}
+	if *trimSilenceFlag && *videoAnchorsFlag != "" {
+		// The trimmed length isn't known, so links would point too early
+		fmt.Fprintf(os.Stderr, "Error: --video-anchors cannot be used with --trim-silence, which shifts timestamps by an unknown amount\n")
+		os.Exit(1)
+	}
+
+	if *sampleRateFlag <= 0 || *channelsFlag <= 0 {
+		fmt.Fprintf(os.Stderr, "Error: --sample-rate and --channels must be positive integers\n")
+		os.Exit(1)
+	}
+	if *sampleRateFlag != transcribe.WhisperSampleRate || *channelsFlag != transcribe.WhisperChannels {
+		fmt.Fprintf(os.Stderr, "Warning: whisper.cpp expects %d Hz mono audio; using %d Hz, %d channel(s)\n",
+			transcribe.WhisperSampleRate, *sampleRateFlag, *channelsFlag)
+	}
+
+	// Validate languages
+	if !languagePattern.MatchString(*languageFlag) {
+		fmt.Fprintf(os.Stderr, "Error: invalid language '%s'. Use a code like en, es, or auto\n", *languageFlag)
+		os.Exit(1)
+	}
+	postLanguage := *postLanguageFlag
+	if postLanguage == "" {
+		postLanguage = *languageFlag
+		if postLanguage == "auto" {
+			postLanguage = "en"
+		}
+	}
+	if postLanguage == "auto" || !languagePattern.MatchString(postLanguage) {
+		fmt.Fprintf(os.Stderr, "Error: invalid post language '%s'. Use a code like en or es\n", postLanguage)
+		os.Exit(1)
+	}
+
+	var hallucinations []string
+	if *hallucinationFilterFlag || *hallucinationListFlag != "" {
+		hallucinations = transcribe.HallucinationPhrases(*languageFlag)
+		if *hallucinationListFlag != "" {
+			extra, err := transcribe.LoadHallucinationList(*hallucinationListFlag)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			hallucinations = append(hallucinations, extra...)
+		}
+	}
+
+	// Resolve the model directory: --model-dir, then $WHISPER_MODEL_DIR, then the default
+	modelDir := *modelDirFlag
+	if modelDir == "" {
+		modelDir = os.Getenv(transcribe.ModelDirEnv)
+	}
+	if modelDir != "" && !*downloadModelFlag {
+		if info, err := os.Stat(modelDir); err != nil || !info.IsDir() {
+			fmt.Fprintf(os.Stderr, "Error: model directory does not exist: %s\n", modelDir)
+			os.Exit(1)
+		}
+	}
+
+	// Expand directories into the videos they contain. Watch mode finds its
+	// inputs as they arrive.
+	var inputs []string
+	if watchMode {
+		if info, err := os.Stat(args[0]); len(args) != 1 || err != nil || !info.IsDir() {
+			fmt.Fprintf(os.Stderr, "Error: watch takes a single existing directory\n")
+			os.Exit(1)
+		}
+		if compareModels != nil || *watchIntervalFlag <= 0 {
+			fmt.Fprintf(os.Stderr, "Error: watch needs a positive --watch-interval and cannot be used with --compare-models\n")
+			os.Exit(1)
+		}
+	} else {
+		var err error
+		if inputs, err = collectInputs(args); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	batch := len(inputs) > 1 || watchMode
+	if *ffmpegJobsFlag < 1 || *whisperJobsFlag < 1 {
+		fmt.Fprintf(os.Stderr, "Error: --ffmpeg-jobs and --whisper-jobs must be at least 1\n")
+		os.Exit(1)
+	}
+	if *onlyChangedFlag && *noCacheFlag {
+		fmt.Fprintf(os.Stderr, "Error: --only-changed relies on the transcript cache and cannot be used with --no-cache\n")
+		os.Exit(1)
+	}
+	if batch && (*outputFlag != "" || *mergeEditsFlag != "" || *cmsJSONFlag != "") {
+		fmt.Fprintf(os.Stderr, "Error: --output, --merge-edits, and --cms-json cannot be used with multiple inputs\n")
+		os.Exit(1)
+	}
+	if *cmsJSONFlag != "" {
+		if *stageFlag || *jsonFlag {
+			fmt.Fprintf(os.Stderr, "Error: --cms-json cannot be used with --stage or --json\n")
+			os.Exit(1)
+		}
+		if err := validateOutputPath(*cmsJSONFlag, inputs[0], *styleFlag); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if _, err := os.Stat(*cmsJSONFlag); err == nil && !*forceFlag {
+			fmt.Fprintf(os.Stderr, "Error: output file already exists: %s\nUse --force to overwrite\n", *cmsJSONFlag)
+			os.Exit(1)
+		}
+	}
+	if *keywordsFlag && (*stageFlag || *jsonFlag) {
+		fmt.Fprintf(os.Stderr, "Error: --keywords cannot be used with --stage or --json\n")
+		os.Exit(1)
+	}
+	if *maxKeywordsFlag < 1 {
+		fmt.Fprintf(os.Stderr, "Error: --max-keywords must be at least 1\n")
+		os.Exit(1)
+	}
+	if *outBundleFlag != "" {
+		if *outputFlag != "" || *stageFlag {
+			fmt.Fprintf(os.Stderr, "Error: --out-bundle cannot be used with --output or --stage\n")
+			os.Exit(1)
+		}
+		if err := validateOutputPath(*outBundleFlag); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if err := os.MkdirAll(*outBundleFlag, 0755); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to create bundle directory: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	var transcriptCache *cache.Cache
+	if !*noCacheFlag {
+		transcriptCache, err = cache.New(*cacheDirFlag)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		transcriptCache.Readable = *cacheReadableFlag
+	}
+
+	usage := &blog.Usage{}
+	base := config{
+		Options: pipeline.Options{
+			Transcribe: transcribe.Options{
+				ModelSize:          *modelFlag,
+				ModelDir:           modelDir,
+				Diarize:            *diarizeFlag,
+				TrimSilence:        *trimSilenceFlag,
+				SilenceThreshold:   *silenceThresholdFlag,
+				SilencePad:         *silencePadFlag,
+				Language:           *languageFlag,
+				SampleRate:         *sampleRateFlag,
+				Channels:           *channelsFlag,
+				TranscriberCmd:     *transcriberCmdFlag,
+				AutoChunkOnTimeout: *autoChunkFlag,
+				SampleDuration:     *sampleFlag,
+				SkipIntro:          *skipIntroFlag,
+				RangeStart:         rangeStart,
+				RangeEnd:           rangeEnd,
+				ProgressFile:       *progressFileFlag,
+				KeepProgressFile:   *keepProgressFileFlag,
+				Limits:             transcribe.NewLimits(*ffmpegJobsFlag, *whisperJobsFlag),
+			},
+			Blog: blog.Options{
+				MaxOutputSize: *maxOutputSizeFlag,
+				Language:      postLanguage,
+				Usage:         usage,
+				Explain:       *explainFlag,
+				ContextDir:    *contextDirFlag,
+				SystemPrompt:  systemPrompt,
+			},
+		},
+		sourceLink:  *sourceLinkFlag,
+		proofread:   *proofreadFlag,
+		stage:       *stageFlag,
+		json:        *jsonFlag,
+		selectAudio: *selectAudioFlag,
+		budget:      &budget{limit: *budgetFlag, usage: usage},
+		cache:       transcriptCache,
+		feedPath:    *feedFlag,
+		feedBaseURL: *feedBaseURLFlag,
+		feedAuthor:  *feedAuthorFlag,
+		seriesDir:   *seriesDirFlag,
+		maxHeading:  *maxHeadingDepthFlag,
+		onlyChanged: *onlyChangedFlag,
+		force:       *forceFlag,
+		statsPath:   *statsFlag,
+		fileMode:    fileMode,
+
+		skipIntroSentences: *skipIntroSentencesFlag,
+		minSegmentGap:      *minSegmentGapFlag,
+		lintStyle:          *lintStyleFlag,
+		videoAnchors:       *videoAnchorsFlag,
+		normalize:          *normalizeWhitespaceFlag,
+		editsPath:          *mergeEditsFlag,
+		forceTranscribe:    *forceTranscribeFlag,
+		hallucinations:     hallucinations,
+		cmsPath:            *cmsJSONFlag,
+		cmsTags:            parseTags(*cmsTagsFlag),
+		cmsCoverImage:      *cmsCoverImageFlag,
+		titlePrefix:        *titlePrefixFlag,
+		titleSuffix:        *titleSuffixFlag,
+	}
+	if strings.Contains(*titlePrefixFlag+*titleSuffixFlag, titleNumberPlaceholder) {
+		base.titleNumbers = newTitleCounter(*titleCounterFlag)
+	}
+	if *keywordsFlag {
+		base.maxKeywords = *maxKeywordsFlag
+	}
+
+	// Pick the style guide: explicit --style wins, then a per-video sibling
+	styleExplicit := false
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "style":
+			styleExplicit = true
+		case "file-mode":
+			base.fileModeSet = true
+		}
+	})
+	styleDir := *styleDirFlag
+	if styleDir == "" {
+		styleDir = filepath.Dir(*styleFlag)
+	}
+
+	// prepare validates an input and builds its config
+	prepare := func(videoPath string) (config, error) {
+		cfg := base
+
+		// Validate video file extension (an existing post is accepted for --proofread)
+		ext := strings.ToLower(filepath.Ext(videoPath))
+		cfg.proofreadOnly = cfg.proofread && ext == ".md"
+		if !validVideoExtensions[ext] && !cfg.proofreadOnly {
+			return cfg, fmt.Errorf("unsupported video format '%s'. Supported formats: mp4, mov, avi, mkv, webm, m4v, wmv, flv", ext)
+		}
+
+		cfg.Blog.StylePath = resolveStylePath(videoPath, *styleFlag, styleExplicit, styleDir, postLanguage)
+		if cfg.Transcribe.ProgressFile != "" {
+			cfg.Transcribe.ProgressFile = progressFileFor(cfg.Transcribe.ProgressFile, videoPath, batch)
+		}
+
+		// Determine output path
+		cfg.outputPath = *outputFlag
+		if *outBundleFlag != "" {
+			cfg.bundleDir = bundleDirFor(*outBundleFlag, videoPath, batch)
+			if err := os.MkdirAll(cfg.bundleDir, 0755); err != nil {
+				return cfg, fmt.Errorf("failed to create bundle directory: %w", err)
+			}
+			cfg.outputPath = filepath.Join(cfg.bundleDir, bundlePost)
+			if cfg.json {
+				cfg.outputPath = filepath.Join(cfg.bundleDir, bundleTranscriptJSON)
+			}
+		} else if cfg.outputPath == "" {
+			baseName := filepath.Base(videoPath)
+			vidExt := filepath.Ext(baseName)
+			nameWithoutExt := strings.TrimSuffix(baseName, vidExt)
+			cfg.outputPath = nameWithoutExt + ".md"
+			if cfg.json {
+				cfg.outputPath = nameWithoutExt + ".json"
+			}
+		}
+
+		// Validate output path (prevent path traversal and overwriting inputs).
+		// An existing post given to --proofread may be rewritten in place.
+		protected := []string{cfg.Blog.StylePath, *styleFlag}
+		if !cfg.proofreadOnly {
+			protected = append(protected, videoPath)
+		}
+		if err := validateOutputPath(cfg.outputPath, protected...); err != nil {
+			return cfg, err
+		}
+
+		// Check for overwrite (--only-changed decides per item whether to regenerate)
+		if !*forceFlag && !cfg.onlyChanged && !cfg.lintStyle {
+			if _, err := os.Stat(cfg.outputPath); err == nil {
+				return cfg, fmt.Errorf("output file already exists: %s\nUse --force to overwrite", cfg.outputPath)
+			}
+		}
+
+		return cfg, nil
+	}
+
+	// Cancel the pipeline on Ctrl+C or SIGTERM so child processes are stopped
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if *downloadModelFlag && *transcriberCmdFlag == "" {
+		models := compareModels
+		if models == nil {
+			models = []string{*modelFlag}
+		}
+		for _, model := range models {
+			if err := transcribe.DownloadModel(ctx, modelDir, model, transcribe.DownloadOptions{
+				Retries: *downloadRetriesFlag,
+				Timeout: *downloadTimeoutFlag,
+			}); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+	}
+
+	// Each worker holds at most one ffmpeg or whisper slot at a time, so this
+	// many keeps both stages busy. Interactive track selection needs the
+	// terminal to itself.
+	workers := *ffmpegJobsFlag + *whisperJobsFlag
+	if *selectAudioFlag {
+		workers = 1
+	}
+
+	if watchMode {
+		if err := runWatch(ctx, args[0], *watchIntervalFlag, prepare, base, workers); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if compareModels != nil {
+		if err := runCompare(ctx, inputs[0], compareModels, base.Transcribe); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
+		return
 	}
 
-	// Run the pipeline
-	if err := run(videoPath, *modelFlag, *styleFlag, outputPath); err != nil {
+	if len(inputs) > 1 {
+		if err := runBatch(ctx, inputs, prepare, base.budget, workers, *failFastFlag); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	cfg, err := prepare(inputs[0])
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
+	if err := runItem(ctx, inputs[0], cfg); err != nil {
+		if errors.Is(err, errUnchanged) {
+			fmt.Printf("Skipped %s: %v\n", inputs[0], err)
+			return
+		}
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// errOutputIsInput is returned when the output path would overwrite one of
+// the run's input files
+var errOutputIsInput = errors.New("output path would overwrite an input file")
+
+// loadSystemPrompt returns the system prompt from --system-prompt or
+// --system-prompt-file; at most one may be set
+func loadSystemPrompt(text, path string) (string, error) {
+	if path == "" {
+		return strings.TrimSpace(text), nil
+	}
+	if text != "" {
+		return "", fmt.Errorf("--system-prompt and --system-prompt-file cannot be used together")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read system prompt: %w", err)
+	}
+	prompt := strings.TrimSpace(string(data))
+	if prompt == "" {
+		return "", fmt.Errorf("system prompt file is empty: %s", path)
+	}
+	return prompt, nil
+}
+
+// parseRange parses a --range value of the form start-end, where each side
+// is a timestamp like 1:05:00, 12:30, or 90
+func parseRange(s string) (time.Duration, time.Duration, error) {
+	startText, endText, ok := strings.Cut(s, "-")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid --range '%s'. Use start-end, e.g. 1:05:00-1:20:00", s)
+	}
+	start, err := parseTimestamp(startText)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid --range start: %w", err)
+	}
+	end, err := parseTimestamp(endText)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid --range end: %w", err)
+	}
+	if start >= end {
+		return 0, 0, fmt.Errorf("invalid --range '%s': start must be before end", s)
+	}
+	return start, end, nil
 }
 
-// validateOutputPath checks for path traversal and ensures the output directory exists
-func validateOutputPath(outputPath string) error {
+// parseTimestamp parses [[h:]m:]s, where seconds may have a fraction
+func parseTimestamp(s string) (time.Duration, error) {
+	parts := strings.Split(strings.TrimSpace(s), ":")
+	if len(parts) > 3 {
+		return 0, fmt.Errorf("invalid timestamp '%s'", s)
+	}
+	var total float64
+	for i, part := range parts {
+		value, err := strconv.ParseFloat(part, 64)
+		if err != nil || value < 0 || (i > 0 && value >= 60) || (i < len(parts)-1 && value != float64(int(value))) {
+			return 0, fmt.Errorf("invalid timestamp '%s'", s)
+		}
+		total = total*60 + value
+	}
+	return time.Duration(total * float64(time.Second)), nil
+}
+
+// postContent returns the bytes to write for a post, normalizing its
+// whitespace unless disabled
+func postContent(post string, normalize bool) []byte {
+	if normalize {
+		return []byte(blog.NormalizeWhitespace(post))
+	}
+	return []byte(post + "\n")
+}
+
+// progressFileFor returns the progress file for a video. A single input
+// uses path as given; in batch mode the video's name is inserted before the
+// extension so concurrent items don't overwrite each other's progress.
+func progressFileFor(path, videoPath string, batch bool) string {
+	if !batch {
+		return path
+	}
+	name := filepath.Base(videoPath)
+	name = strings.TrimSuffix(name, filepath.Ext(name))
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "." + name + ext
+}
+
+// parseFileMode parses an octal permission string such as 0644 or 664
+func parseFileMode(s string) (os.FileMode, error) {
+	mode, err := strconv.ParseUint(s, 8, 32)
+	if err != nil || mode > 0777 {
+		return 0, fmt.Errorf("invalid --file-mode '%s'. Use octal permissions like 0644 or 0600", s)
+	}
+	if mode&0600 != 0600 {
+		return 0, fmt.Errorf("invalid --file-mode '%s'. The owner needs read and write access", s)
+	}
+	return os.FileMode(mode), nil
+}
+
+// writeOutputFile writes an output or sidecar file with the configured
+// permissions. An explicit --file-mode is applied exactly, bypassing umask
+// and fixing up files that already existed with other permissions.
+func writeOutputFile(path string, data []byte, cfg config) error {
+	if err := os.WriteFile(path, data, cfg.fileMode); err != nil {
+		return err
+	}
+	return applyFileMode(path, cfg)
+}
+
+// applyFileMode sets an explicit --file-mode on a file written by one of the
+// internal packages (drafts, feed, stats), which create files as 0644
+func applyFileMode(path string, cfg config) error {
+	if !cfg.fileModeSet {
+		return nil
+	}
+	if err := os.Chmod(path, cfg.fileMode); err != nil {
+		return fmt.Errorf("failed to set permissions on %s: %w", path, err)
+	}
+	return nil
+}
+
+// validateOutputPath checks for path traversal, ensures the output directory
+// exists, and refuses outputs that resolve to any of the protected input
+// files (the source video and style guide)
+func validateOutputPath(outputPath string, protected ...string) error {
 	// Get absolute path
 	absPath, err := filepath.Abs(outputPath)
 	if err != nil {
@@ -120,40 +777,669 @@ func validateOutputPath(outputPath string) error {
 		return fmt.Errorf("output directory does not exist: %s", parentDir)
 	}
 
+	// Refuse to clobber inputs, even with --force
+	for _, input := range protected {
+		if input != "" && samePath(absPath, input) {
+			return fmt.Errorf("%w: %s", errOutputIsInput, input)
+		}
+	}
+
+	return nil
+}
+
+// samePath reports whether two paths refer to the same file, following
+// symlinks and hard links when the files exist
+func samePath(a, b string) bool {
+	if infoA, err := os.Stat(a); err == nil {
+		if infoB, err := os.Stat(b); err == nil {
+			return os.SameFile(infoA, infoB)
+		}
+	}
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	return errA == nil && errB == nil && absA == absB
+}
+
+// printGPUInfo reports the GPU backend detected in the whisper.cpp build
+func printGPUInfo(opts transcribe.Options) error {
+	if opts.ModelDir == "" {
+		opts.ModelDir = os.Getenv(transcribe.ModelDirEnv)
+	}
+
+	fmt.Printf("Probing whisper.cpp with model: %s\n", transcribe.ModelPath(opts.ModelDir, opts.ModelSize))
+	info, err := transcribe.ProbeGPU(context.Background(), opts)
+	if err != nil {
+		return err
+	}
+
+	if info.Backend == "" {
+		fmt.Println("GPU info unavailable: no GPU backend reported (CPU-only build or no device found)")
+	} else {
+		fmt.Printf("GPU backend active: %s\n", info.Backend)
+	}
+	for _, line := range info.Details {
+		fmt.Printf("  %s\n", line)
+	}
+	return nil
+}
+
+// isTerminal reports whether f is attached to a terminal
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// selectAudioTrack lists the video's audio streams and asks the user to pick
+// one. It returns track 0 without prompting when stdin is not a TTY or when
+// there is only a single track.
+func selectAudioTrack(ctx context.Context, videoPath string) (int, error) {
+	if !isTerminal(os.Stdin) {
+		return 0, nil
+	}
+
+	tracks, err := transcribe.ListAudioTracks(ctx, videoPath)
+	if err != nil {
+		return 0, err
+	}
+	if len(tracks) == 0 {
+		return 0, fmt.Errorf("no audio tracks found in %s", videoPath)
+	}
+	if len(tracks) == 1 {
+		return 0, nil
+	}
+
+	fmt.Println("Audio tracks:")
+	for _, t := range tracks {
+		lang := t.Language
+		if lang == "" {
+			lang = "und"
+		}
+		fmt.Printf("  [%d] %s, %d ch, %s", t.Index, t.Codec, t.Channels, lang)
+		if t.Title != "" {
+			fmt.Printf(", %q", t.Title)
+		}
+		fmt.Println()
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		fmt.Printf("Select audio track [0-%d] (default 0): ", len(tracks)-1)
+		line, err := readLine(ctx, reader)
+		if ctx.Err() != nil {
+			fmt.Println()
+			return 0, ctx.Err()
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			return 0, nil
+		}
+		n, convErr := strconv.Atoi(line)
+		if convErr == nil && n >= 0 && n < len(tracks) {
+			return n, nil
+		}
+		if err != nil {
+			return 0, fmt.Errorf("invalid audio track selection: %s", line)
+		}
+		fmt.Printf("Invalid selection: %s\n", line)
+	}
+}
+
+// readLine reads a line from reader, giving up when ctx is canceled. A read
+// that is still blocked then is left to finish in the background.
+func readLine(ctx context.Context, reader *bufio.Reader) (string, error) {
+	type result struct {
+		line string
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		line, err := reader.ReadString('\n')
+		done <- result{line, err}
+	}()
+	select {
+	case r := <-done:
+		return r.line, r.err
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// resolveStylePath chooses the style guide for a video: a sibling
+// <videobase>.style.md if present, then style.<lang>.md in styleDir for the
+// post language, falling back to the global style guide path. An explicit
+// --style only overrides the per-video sibling, so per-language guides still
+// apply.
+func resolveStylePath(videoPath, stylePath string, explicit bool, styleDir, language string) string {
+	var candidates []string
+	if !explicit {
+		candidates = append(candidates, strings.TrimSuffix(videoPath, filepath.Ext(videoPath))+".style.md")
+	}
+	candidates = append(candidates, filepath.Join(styleDir, "style."+language+".md"))
+	for _, candidate := range candidates {
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			return candidate
+		}
+	}
+	return stylePath
+}
+
+// describeStyle returns a human-readable label for the chosen style guide
+func describeStyle(stylePath string) string {
+	if _, err := os.Stat(stylePath); os.IsNotExist(err) && stylePath == "style_guide.md" {
+		return "built-in default"
+	}
+	return stylePath
+}
+
+// config holds the settings for a single pipeline run
+type config struct {
+	pipeline.Options
+	outputPath string
+	bundleDir  string // --out-bundle directory for this input; empty when not bundling
+	sourceLink string
+	proofread  bool
+	stage      bool // Write to the drafts queue instead of outputPath
+
+	proofreadOnly bool // Input is an existing post to proofread
+	json          bool // Write the timed transcript instead of a post
+	selectAudio   bool // Prompt for the audio track
+	budget        *budget
+	cache         *cache.Cache // Transcript cache; nil when disabled
+	feedPath      string       // Atom feed to update; empty to skip
+	feedBaseURL   string
+	feedAuthor    string // Author of a newly created feed
+	seriesDir     string // Earlier posts to summarize into the prompt; empty to skip
+	maxHeading    int    // Deepest heading level kept; 0 keeps all
+	onlyChanged   bool   // Skip items whose inputs match the last recorded run
+	force         bool
+	statsPath     string // CSV file for per-post stats; empty to skip
+	fileMode      os.FileMode
+	fileModeSet   bool // Apply fileMode exactly instead of leaving it to umask
+
+	skipIntroSentences int           // Sentences dropped from the start of the transcript
+	minSegmentGap      time.Duration // Merge --json segments split by less than this
+	lintStyle          bool          // Print a style guide critique instead of writing a post
+	videoAnchors       string        // Hosted video URL for paragraph timestamp links; empty to skip
+	normalize          bool          // Tidy the post's whitespace before writing
+	editsPath          string        // Hand-edited transcript that replaces transcription when present
+	forceTranscribe    bool          // Ignore cached and edited transcripts
+	hallucinations     []string      // Whisper artifact phrases to filter out; nil disables the filter
+	cmsPath            string        // CMS import record to write; empty to skip
+	cmsTags            []string
+	cmsCoverImage      string
+	titlePrefix        string // Added to the post title; {n} takes the next counter number
+	titleSuffix        string
+	titleNumbers       *titleCounter // Source of {n} in the title affixes; nil when unused
+	titleSeq           int           // This item's position in the run, for numbering in input order
+	maxKeywords        int           // Terms written to the keywords sidecar; 0 to skip it
+}
+
+// runProofread copyedits an existing post and writes the result
+func runProofread(ctx context.Context, postPath string, cfg config) error {
+	fmt.Printf("Proofreading post: %s\n", postPath)
+	fmt.Printf("Using style guide: %s (language: %s)\n", describeStyle(cfg.Blog.StylePath), cfg.Blog.Language)
+
+	data, err := os.ReadFile(postPath)
+	if err != nil {
+		return fmt.Errorf("failed to read post: %w", err)
+	}
+
+	blogPost, err := proofreadCached(ctx, strings.TrimSpace(string(data)), cfg)
+	if err != nil {
+		return err
+	}
+
+	if err := writeOutputFile(cfg.outputPath, postContent(blogPost, cfg.normalize), cfg); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+
+	fmt.Printf("\nProofread post saved to: %s\n", cfg.outputPath)
 	return nil
 }
 
-func run(videoPath, modelSize, stylePath, outputPath string) error {
+// proofreadCached copyedits a post, reusing the cached result for the same
+// text, style guide, system prompt, and model. Returns errBudgetExhausted if
+// the result isn't cached and the budget can't cover the call.
+func proofreadCached(ctx context.Context, post string, cfg config) (string, error) {
+	key := cache.HashText(strings.Join([]string{
+		post, styleHash(cfg.Blog.StylePath), cfg.Blog.Language, cfg.Blog.SystemPrompt, pipeline.BlogBackend,
+	}, "\x00"))
+	if cfg.cache != nil {
+		proofread, ok, err := cfg.cache.Get("proofread", key)
+		if err != nil {
+			return "", err
+		}
+		if ok {
+			fmt.Println("Using cached proofread")
+			return proofread, nil
+		}
+	}
+
+	if !cfg.budget.allowsCall() {
+		return "", errBudgetExhausted
+	}
+	proofread, err := blog.Proofread(ctx, post, cfg.Blog)
+	if err != nil {
+		return "", fmt.Errorf("proofreading failed: %w", err)
+	}
+
+	if cfg.cache != nil {
+		if err := cfg.cache.Put("proofread", key, proofread); err != nil {
+			return "", err
+		}
+	}
+	return proofread, nil
+}
+
+// runTranscriptJSON transcribes a video and writes its timed segments as
+// JSON. With diarization, consecutive segments from the same speaker are
+// merged into speaker blocks.
+func runTranscriptJSON(ctx context.Context, videoPath string, cfg config) error {
 	fmt.Printf("Processing video: %s\n", videoPath)
-	fmt.Printf("Using whisper model: %s\n", modelSize)
+	fmt.Printf("Using whisper model: %s (%s)\n", cfg.Transcribe.ModelSize, transcribe.ModelPath(cfg.Transcribe.ModelDir, cfg.Transcribe.ModelSize))
 
-	// Step 1: Transcribe video
-	fmt.Println("\n[1/3] Transcribing video...")
-	transcript, err := transcribe.TranscribeVideo(videoPath, modelSize)
+	fmt.Println("\n[1/2] Transcribing video...")
+	segments, err := transcribe.TranscribeSegments(ctx, videoPath, cfg.Transcribe)
+	if err != nil {
+		return fmt.Errorf("transcription failed: %w", err)
+	}
+	if cfg.Transcribe.Diarize {
+		segments = transcribe.MergeSpeakerTurns(segments)
+	}
+	if cfg.hallucinations != nil {
+		kept := segments[:0]
+		for _, seg := range segments {
+			if !transcribe.IsHallucination(seg.Text, cfg.hallucinations) {
+				kept = append(kept, seg)
+			}
+		}
+		fmt.Printf("Hallucination filter removed %d artifact segment(s)\n", len(segments)-len(kept))
+		segments = kept
+	}
+	segments = transcribe.MergeShortGaps(segments, cfg.minSegmentGap)
+	fmt.Printf("Transcription complete (%d segments)\n", len(segments))
+
+	data, err := json.MarshalIndent(struct {
+		Segments []transcribe.Segment `json:"segments"`
+	}{segments}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode transcript: %w", err)
+	}
+
+	fmt.Println("\n[2/2] Writing output file...")
+	if err := writeOutputFile(cfg.outputPath, append(data, '\n'), cfg); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+
+	fmt.Printf("\nTranscript saved to: %s\n", cfg.outputPath)
+	return nil
+}
+
+// transcribeCached returns the cached transcript for a video when available,
+// otherwise transcribes it and caches the result. An existing --merge-edits
+// file takes precedence and replaces the cached transcript. The cache key is
+// returned so the output can be linked to its transcript; it is empty when
+// caching is disabled.
+func transcribeCached(ctx context.Context, videoPath string, cfg config) (string, string, error) {
+	var key string
+	if cfg.cache != nil {
+		var err error
+		if key, err = cache.Key(videoPath, cfg.Transcribe.Fingerprint()); err != nil {
+			return "", "", err
+		}
+	}
+
+	// A hand-edited transcript is authoritative unless --force-transcribe
+	if cfg.editsPath != "" && !cfg.forceTranscribe {
+		data, err := os.ReadFile(cfg.editsPath)
+		if err == nil {
+			transcript := strings.TrimSpace(string(data))
+			if transcript == "" {
+				return "", "", fmt.Errorf("edited transcript is empty: %s", cfg.editsPath)
+			}
+			fmt.Printf("Using edited transcript: %s\n", cfg.editsPath)
+			if cfg.cache != nil {
+				if err := cfg.cache.PutTranscript(key, transcript, videoPath); err != nil {
+					return "", "", err
+				}
+			}
+			return transcript, key, nil
+		}
+		if !os.IsNotExist(err) {
+			return "", "", fmt.Errorf("failed to read edited transcript: %w", err)
+		}
+	}
+
+	if cfg.cache != nil && !cfg.forceTranscribe {
+		transcript, ok, err := cfg.cache.Transcript(key)
+		if err != nil {
+			return "", "", err
+		}
+		if ok {
+			fmt.Println("Using cached transcript")
+			return transcript, key, nil
+		}
+	}
+
+	transcript, err := pipeline.Transcribe(ctx, videoPath, cfg.Options)
+	if err != nil {
+		return "", "", err
+	}
+	if cfg.cache != nil {
+		if err := cfg.cache.PutTranscript(key, transcript, videoPath); err != nil {
+			return "", "", err
+		}
+	}
+
+	// Give the user a starting point to correct by hand
+	if cfg.editsPath != "" {
+		if _, err := os.Stat(cfg.editsPath); os.IsNotExist(err) {
+			if err := writeOutputFile(cfg.editsPath, []byte(transcript+"\n"), cfg); err != nil {
+				return "", "", fmt.Errorf("failed to save transcript for editing: %w", err)
+			}
+			fmt.Printf("Transcript saved for editing to: %s\n", cfg.editsPath)
+		}
+	}
+	return transcript, key, nil
+}
+
+// segmentsCached returns the video's timed segments, caching them next to the
+// transcript. On a miss the transcript cache is filled from the same run.
+func segmentsCached(ctx context.Context, videoPath string, cfg config) ([]transcribe.Segment, error) {
+	if cfg.cache == nil {
+		return transcribe.TranscribeSegments(ctx, videoPath, cfg.Transcribe)
+	}
+
+	key, err := cache.Key(videoPath, cfg.Transcribe.Fingerprint())
+	if err != nil {
+		return nil, err
+	}
+	if data, ok, err := cfg.cache.Get("segments", key); err != nil {
+		return nil, err
+	} else if ok && !cfg.forceTranscribe {
+		var segments []transcribe.Segment
+		if err := json.Unmarshal([]byte(data), &segments); err == nil {
+			return segments, nil
+		}
+	}
+
+	segments, err := transcribe.TranscribeSegments(ctx, videoPath, cfg.Transcribe)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(segments)
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.cache.Put("segments", key, string(data)); err != nil {
+		return nil, err
+	}
+	if _, ok, err := cfg.cache.Transcript(key); err == nil && !ok {
+		if err := cfg.cache.PutTranscript(key, segmentsText(segments), videoPath); err != nil {
+			return nil, err
+		}
+	}
+	return segments, nil
+}
+
+// segmentsText joins segment texts into a plain transcript, one per line as
+// whisper.cpp writes them
+func segmentsText(segments []transcribe.Segment) string {
+	texts := make([]string, len(segments))
+	for i, seg := range segments {
+		texts[i] = seg.Text
+	}
+	return strings.Join(texts, "\n")
+}
+
+// recordOutput links the written post to its cached transcript so it can be
+// regenerated later. Failures only warn since the post itself was written.
+func recordOutput(cfg config, cacheKey, videoPath string) {
+	if cfg.cache == nil || cacheKey == "" {
+		return
+	}
+	if err := cfg.cache.RecordOutput(cfg.outputPath, cacheKey, videoPath, styleHash(cfg.Blog.StylePath)); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+}
+
+// feedLink returns the link used in the feed for a local file: its
+// slash-separated path, under --feed-base-url when set
+func feedLink(path string, cfg config) string {
+	link := filepath.ToSlash(filepath.Clean(path))
+	if cfg.feedBaseURL != "" {
+		link = strings.TrimSuffix(cfg.feedBaseURL, "/") + "/" + link
+	}
+	return link
+}
+
+// feedMu serializes feed updates from concurrent batch items
+var feedMu sync.Mutex
+
+// addFeedEntry adds an entry for the written post to the Atom feed
+func addFeedEntry(cfg config, blogPost string) error {
+	feedMu.Lock()
+	defer feedMu.Unlock()
+
+	f, err := feed.Load(cfg.feedPath, cfg.feedAuthor, feedLink(cfg.feedPath, cfg))
 	if err != nil {
+		return err
+	}
+
+	title := blog.Title(blogPost)
+	if title == "" {
+		title = strings.TrimSuffix(filepath.Base(cfg.outputPath), filepath.Ext(cfg.outputPath))
+	}
+
+	summary := blog.Excerpt(blog.StripExplainComments(blogPost), 280)
+	f.Add(feed.NewEntry(title, summary, feedLink(cfg.outputPath, cfg), time.Now()))
+	if err := f.Save(cfg.feedPath); err != nil {
+		return err
+	}
+	return applyFileMode(cfg.feedPath, cfg)
+}
+
+func run(ctx context.Context, videoPath string, cfg config) error {
+	start := time.Now()
+	fmt.Printf("Processing video: %s\n", videoPath)
+	fmt.Printf("Using whisper model: %s (%s)\n", cfg.Transcribe.ModelSize, transcribe.ModelPath(cfg.Transcribe.ModelDir, cfg.Transcribe.ModelSize))
+	fmt.Printf("Using style guide: %s (language: %s)\n", describeStyle(cfg.Blog.StylePath), cfg.Blog.Language)
+
+	// Step 1: Transcribe video. Segments come first when anchoring so a single
+	// whisper run fills both caches.
+	fmt.Println("\n[1/3] Transcribing video...")
+	var segments []transcribe.Segment
+	var err error
+	if cfg.videoAnchors != "" {
+		segments, err = segmentsCached(ctx, videoPath, cfg)
+		if err != nil {
+			return fmt.Errorf("transcription failed: %w", err)
+		}
+	}
+	var transcript, cacheKey string
+	if segments != nil && cfg.cache == nil {
+		transcript = segmentsText(segments)
+	} else if transcript, cacheKey, err = transcribeCached(ctx, videoPath, cfg); err != nil {
 		return fmt.Errorf("transcription failed: %w", err)
 	}
 	fmt.Printf("Transcription complete (%d characters)\n", len(transcript))
 
+	if cfg.hallucinations != nil {
+		var removed int
+		transcript, removed = transcribe.FilterHallucinations(transcript, cfg.hallucinations)
+		fmt.Printf("Hallucination filter removed %d artifact line(s)\n", removed)
+		if strings.TrimSpace(transcript) == "" {
+			return fmt.Errorf("transcript has no text left after removing whisper artifacts")
+		}
+	}
+
+	if cfg.skipIntroSentences > 0 {
+		transcript = transcribe.SkipSentences(transcript, cfg.skipIntroSentences)
+		if transcript == "" {
+			return fmt.Errorf("transcript has no text left after skipping %d intro sentence(s)", cfg.skipIntroSentences)
+		}
+		fmt.Printf("Skipped %d intro sentence(s) (%d characters left)\n", cfg.skipIntroSentences, len(transcript))
+	}
+
+	// Keep the transcript rather than start an LLM call the budget can't cover
+	if !cfg.budget.allowsCall() {
+		if err := saveTranscript(cfg, transcript); err != nil {
+			return err
+		}
+		return errBudgetExhausted
+	}
+
 	// Step 2: Convert to blog post
 	fmt.Println("\n[2/3] Converting to blog post...")
-	blogPost, err := blog.ConvertToBlog(transcript, stylePath)
+	if cfg.seriesDir != "" {
+		fmt.Printf("Summarizing earlier posts in %s...\n", cfg.seriesDir)
+		topics, err := seriesTopics(ctx, cfg.seriesDir, cfg)
+		if errors.Is(err, errBudgetExhausted) {
+			if err := saveTranscript(cfg, transcript); err != nil {
+				return err
+			}
+		}
+		if err != nil {
+			return err
+		}
+		cfg.Blog.CoveredTopics = topics
+	}
+	blogPost, err := pipeline.Blog(ctx, transcript, cfg.Options)
 	if err != nil {
 		return fmt.Errorf("blog conversion failed: %w", err)
 	}
+	return finishPost(ctx, videoPath, transcript, cacheKey, blogPost, segments, cfg, start)
+}
 
+// finishPost post-processes a generated post and writes it with its
+// artifacts, recording the output and updating the feed and stats. It is
+// shared by run and reprocess so regenerated posts get the same treatment.
+// segments are needed only for --video-anchors.
+func finishPost(ctx context.Context, videoPath, transcript, cacheKey, blogPost string, segments []transcribe.Segment, cfg config, start time.Time) error {
 	// Validate blog content before writing
+	var err error
 	blogPost = strings.TrimSpace(blogPost)
 	if blogPost == "" {
 		return fmt.Errorf("generated blog post is empty")
 	}
 
+	if cfg.proofread {
+		proofread, err := proofreadCached(ctx, blogPost, cfg)
+		switch {
+		case errors.Is(err, errBudgetExhausted):
+			fmt.Println("Skipping proofread: budget exhausted")
+		case err != nil:
+			return err
+		default:
+			blogPost = proofread
+		}
+	}
+
+	blogPost = blog.FlattenHeadings(blogPost, cfg.maxHeading)
+
+	if cfg.videoAnchors != "" {
+		spans := make([]blog.Span, len(segments))
+		for i, seg := range segments {
+			spans[i] = blog.Span{Start: seg.Start, Text: seg.Text}
+		}
+		blogPost = blog.AddVideoAnchors(blogPost, spans, cfg.videoAnchors)
+	}
+
+	if cfg.sourceLink != "" {
+		blogPost = blog.AddSourceLink(blogPost, cfg.sourceLink)
+	}
+
+	// Number the title last; the number is recorded once the post is written
+	blogPost, commitTitle, err := applyTitleAffixes(blogPost, cfg)
+	if err != nil {
+		return err
+	}
+
 	// Step 3: Write output file
 	fmt.Println("\n[3/3] Writing output file...")
-	if err := os.WriteFile(outputPath, []byte(blogPost+"\n"), 0644); err != nil {
-		return fmt.Errorf("failed to write output: %w", err)
+	if cfg.stage {
+		draftPath, err := drafts.Stage(drafts.DefaultDir, videoPath, cfg.outputPath, postContent(blogPost, cfg.normalize))
+		if err != nil {
+			return fmt.Errorf("failed to stage draft: %w", err)
+		}
+		if err := applyFileMode(draftPath, cfg); err != nil {
+			return err
+		}
+		if err := commitTitle(); err != nil {
+			return err
+		}
+		recordOutput(cfg, cacheKey, videoPath)
+		fmt.Printf("\nDraft staged at: %s\n", draftPath)
+		fmt.Printf("Approve it with: video-journal approve %s\n", filepath.Base(draftPath))
+		return nil
+	}
+	artifacts := []artifact{{path: cfg.outputPath, data: postContent(blogPost, cfg.normalize)}}
+	if cfg.bundleDir != "" {
+		extra, err := bundleArtifacts(cfg, videoPath, transcript, blogPost)
+		if err != nil {
+			return err
+		}
+		artifacts = append(artifacts, extra...)
+	}
+	if cfg.cmsPath != "" {
+		record, err := buildCMSPost(blogPost, cfg.cmsTags, cfg.cmsCoverImage, time.Now())
+		if err != nil {
+			return err
+		}
+		artifacts = append(artifacts, artifact{path: cfg.cmsPath, data: record})
+	}
+	if cfg.maxKeywords > 0 {
+		keywords, err := keywordsArtifact(cfg, blogPost)
+		if err != nil {
+			return err
+		}
+		artifacts = append(artifacts, keywords)
+	}
+	if err := writeArtifacts(artifacts, cfg); err != nil {
+		return err
+	}
+	if err := commitTitle(); err != nil {
+		return err
+	}
+
+	recordOutput(cfg, cacheKey, videoPath)
+	fmt.Printf("\nBlog post saved to: %s\n", cfg.outputPath)
+	if cfg.bundleDir != "" {
+		fmt.Printf("Bundle written to: %s\n", cfg.bundleDir)
+	}
+	if cfg.cmsPath != "" {
+		fmt.Printf("CMS record saved to: %s\n", cfg.cmsPath)
+	}
+	if cfg.maxKeywords > 0 {
+		fmt.Printf("Keywords saved to: %s\n", keywordsPath(cfg))
 	}
 
-	fmt.Printf("\nBlog post saved to: %s\n", outputPath)
+	if cfg.feedPath != "" {
+		if err := addFeedEntry(cfg, blogPost); err != nil {
+			return err
+		}
+		fmt.Printf("Feed updated: %s\n", cfg.feedPath)
+	}
+
+	if cfg.statsPath != "" {
+		postStats := blog.Stats(blog.StripExplainComments(blogPost))
+		if err := stats.Append(cfg.statsPath, stats.Row{
+			Date:           time.Now(),
+			Video:          videoPath,
+			Output:         cfg.outputPath,
+			Words:          postStats.Words,
+			Sections:       postStats.Sections,
+			ReadingMinutes: postStats.ReadingMinutes,
+			Model:          cfg.Transcribe.ModelSize,
+			Backend:        pipeline.TranscribeBackend(cfg.Transcribe) + "+" + pipeline.BlogBackend,
+			Duration:       time.Since(start),
+		}); err != nil {
+			return err
+		}
+		if err := applyFileMode(cfg.statsPath, cfg); err != nil {
+			return err
+		}
+		fmt.Printf("Stats appended to: %s\n", cfg.statsPath)
+	}
 	return nil
 }
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/chezu/video-journal/internal/blog"
+	"github.com/chezu/video-journal/internal/cache"
+	"github.com/chezu/video-journal/internal/pipeline"
+)
+
+// runReprocess implements the reprocess subcommand, which regenerates posts
+// from their cached transcripts without touching the source videos
+func runReprocess(args []string) error {
+	fs := flag.NewFlagSet("reprocess", flag.ExitOnError)
+	styleFlag := fs.String("style", "style_guide.md", "Path to style guide file (overrides per-video <name>.style.md)")
+	styleDirFlag := fs.String("style-dir", "", "Directory of per-language style guides named style.<lang>.md (default: directory of --style)")
+	languageFlag := fs.String("post-language", "en", "Language to write the post in")
+	maxOutputSizeFlag := fs.Int("max-output-size", blog.DefaultMaxOutputSize, "Maximum size of the generated post in bytes")
+	proofreadFlag := fs.Bool("proofread", false, "Run a light copyedit pass over each post")
+	systemPromptFlag := fs.String("system-prompt", "", "System prompt setting the model's role")
+	systemPromptFileFlag := fs.String("system-prompt-file", "", "Read the system prompt from this file")
+	normalizeWhitespaceFlag := fs.Bool("normalize-whitespace", true, "Tidy whitespace in each post (trailing spaces, blank line runs, list indentation) outside code blocks")
+	sourceLinkFlag := fs.String("source-link", "", "URL of the source video to reference under each post title")
+	maxHeadingDepthFlag := fs.Int("max-heading-depth", 0, "Turn headings deeper than this level (1-6) into bold paragraphs; 0 keeps all headings")
+	titlePrefixFlag := fs.String("title-prefix", "", "Text to put before each post title; {n} is replaced by the post's number from --title-counter")
+	titleSuffixFlag := fs.String("title-suffix", "", "Text to put after each post title; {n} works as in --title-prefix")
+	titleCounterFlag := fs.String("title-counter", DefaultTitleCounter, "File recording the numbers used for {n}; posts keep the number they were given")
+	contextDirFlag := fs.String("context-dir", "", "Run claude in this directory so it can read project docs there while writing")
+	explainFlag := fs.Bool("explain", false, "Annotate each section with an HTML comment citing its source in the transcript")
+	fileModeFlag := fs.String("file-mode", "0644", "Octal permissions for the rewritten posts; the default is subject to umask")
+	budgetFlag := fs.Float64("budget", 0, "Maximum Claude spend in USD across all posts; 0 means no limit")
+	cacheDirFlag := fs.String("cache-dir", cache.DefaultDir(), "Directory for cached transcripts")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: video-journal reprocess [flags] <post.md|dir>...\n\n")
+		fmt.Fprintf(os.Stderr, "Regenerate posts from their cached transcripts.\n\n")
+		fmt.Fprintf(os.Stderr, "Flags:\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() < 1 {
+		fs.Usage()
+		os.Exit(1)
+	}
+	if *maxOutputSizeFlag <= 0 {
+		return fmt.Errorf("--max-output-size must be a positive number of bytes")
+	}
+	if !languagePattern.MatchString(*languageFlag) || *languageFlag == "auto" {
+		return fmt.Errorf("invalid post language '%s'. Use a code like en or es", *languageFlag)
+	}
+	if *maxHeadingDepthFlag < 0 || *maxHeadingDepthFlag > 6 {
+		return fmt.Errorf("--max-heading-depth must be between 0 and 6")
+	}
+	if *budgetFlag < 0 {
+		return fmt.Errorf("--budget must not be negative")
+	}
+	if *contextDirFlag != "" {
+		if info, err := os.Stat(*contextDirFlag); err != nil || !info.IsDir() {
+			return fmt.Errorf("context directory does not exist: %s", *contextDirFlag)
+		}
+	}
+	fileMode, err := parseFileMode(*fileModeFlag)
+	if err != nil {
+		return err
+	}
+
+	systemPrompt, err := loadSystemPrompt(*systemPromptFlag, *systemPromptFileFlag)
+	if err != nil {
+		return err
+	}
+
+	styleExplicit, fileModeSet := false, false
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "style":
+			styleExplicit = true
+		case "file-mode":
+			fileModeSet = true
+		}
+	})
+	styleDir := *styleDirFlag
+	if styleDir == "" {
+		styleDir = filepath.Dir(*styleFlag)
+	}
+
+	posts, err := collectPosts(fs.Args())
+	if err != nil {
+		return err
+	}
+
+	transcriptCache, err := cache.New(*cacheDirFlag)
+	if err != nil {
+		return err
+	}
+
+	usage := &blog.Usage{}
+	base := config{
+		Options: pipeline.Options{
+			Blog: blog.Options{
+				MaxOutputSize: *maxOutputSizeFlag,
+				Language:      *languageFlag,
+				Usage:         usage,
+				Explain:       *explainFlag,
+				ContextDir:    *contextDirFlag,
+				SystemPrompt:  systemPrompt,
+			},
+		},
+		sourceLink:  *sourceLinkFlag,
+		proofread:   *proofreadFlag,
+		budget:      &budget{limit: *budgetFlag, usage: usage},
+		cache:       transcriptCache,
+		maxHeading:  *maxHeadingDepthFlag,
+		fileMode:    fileMode,
+		fileModeSet: fileModeSet,
+		normalize:   *normalizeWhitespaceFlag,
+		titlePrefix: *titlePrefixFlag,
+		titleSuffix: *titleSuffixFlag,
+	}
+	if strings.Contains(*titlePrefixFlag+*titleSuffixFlag, titleNumberPlaceholder) {
+		base.titleNumbers = newTitleCounter(*titleCounterFlag)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	failed := 0
+	for i, postPath := range posts {
+		if ctx.Err() != nil {
+			return fmt.Errorf("reprocess canceled after %d of %d posts", i, len(posts))
+		}
+		cfg := base
+		cfg.outputPath = postPath
+		cfg.titleSeq = i
+		err := reprocessPost(ctx, postPath, cfg, func(source string) string {
+			return resolveStylePath(source, *styleFlag, styleExplicit, styleDir, *languageFlag)
+		})
+		if cfg.titleNumbers != nil {
+			cfg.titleNumbers.finish(i)
+		}
+		switch {
+		case errors.Is(err, errBudgetExhausted):
+			fmt.Printf("Skipped %s: %v\n", postPath, err)
+		case err != nil:
+			fmt.Fprintf(os.Stderr, "Error: %s: %v\n", postPath, err)
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d posts failed to reprocess", failed, len(posts))
+	}
+	return nil
+}
+
+// collectPosts expands directory arguments into the markdown files they
+// contain (non-recursive)
+func collectPosts(args []string) ([]string, error) {
+	var posts []string
+	for _, arg := range args {
+		info, err := os.Stat(arg)
+		if err != nil || !info.IsDir() {
+			posts = append(posts, arg)
+			continue
+		}
+
+		matches, err := filepath.Glob(filepath.Join(arg, "*.md"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to list posts: %w", err)
+		}
+		posts = append(posts, matches...)
+	}
+	return posts, nil
+}
+
+// reprocessPost regenerates one post from the transcript recorded for it,
+// falling back to a readable-cache transcript of a video with the same name.
+// The new post goes through the same post-processing and write path as a
+// normal run. styleFor picks the style guide given the source video path.
+func reprocessPost(ctx context.Context, postPath string, cfg config, styleFor func(string) string) error {
+	start := time.Now()
+	c := cfg.cache
+	record, ok, err := c.LookupOutput(postPath)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		name := strings.TrimSuffix(filepath.Base(postPath), filepath.Ext(postPath))
+		source, entry, found, err := c.LookupSource(name)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return fmt.Errorf("no cached transcript recorded for this post")
+		}
+		record = cache.OutputRecord{Key: entry.Key, Source: source}
+	}
+	transcript, ok, err := c.Transcript(record.Key)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("cached transcript %s is missing (source: %s)", record.Key, record.Source)
+	}
+
+	cfg.Blog.StylePath = styleFor(record.Source)
+	fmt.Printf("\nReprocessing post: %s\n", postPath)
+	fmt.Printf("Using style guide: %s (language: %s)\n", describeStyle(cfg.Blog.StylePath), cfg.Blog.Language)
+
+	if !cfg.budget.allowsCall() {
+		return errBudgetExhausted
+	}
+	blogPost, err := blog.ConvertToBlog(ctx, transcript, cfg.Blog)
+	if err != nil {
+		return fmt.Errorf("blog conversion failed: %w", err)
+	}
+	return finishPost(ctx, record.Source, transcript, record.Key, blogPost, nil, cfg, start)
+}
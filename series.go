@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/chezu/video-journal/internal/blog"
+	"github.com/chezu/video-journal/internal/cache"
+)
+
+// maxSeriesPosts caps how many earlier posts are summarized for --series-dir
+const maxSeriesPosts = 20
+
+// seriesTopics summarizes the most recent posts in dir, skipping the post
+// being written. Summaries are cached by post content, so unchanged posts are
+// only summarized once.
+func seriesTopics(ctx context.Context, dir string, cfg config) ([]string, error) {
+	matches, err := filepath.Glob(filepath.Join(dir, "*.md"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list series posts: %w", err)
+	}
+
+	type post struct {
+		path    string
+		modTime int64
+	}
+	absOutput, _ := filepath.Abs(cfg.outputPath)
+	var posts []post
+	for _, m := range matches {
+		if absPath, _ := filepath.Abs(m); absPath == absOutput {
+			continue
+		}
+		info, err := os.Stat(m)
+		if err != nil {
+			continue
+		}
+		posts = append(posts, post{m, info.ModTime().UnixNano()})
+	}
+	sort.Slice(posts, func(i, j int) bool { return posts[i].modTime > posts[j].modTime })
+	if len(posts) > maxSeriesPosts {
+		posts = posts[:maxSeriesPosts]
+	}
+
+	var topics []string
+	for _, p := range posts {
+		data, err := os.ReadFile(p.path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read series post: %w", err)
+		}
+		content := blog.StripExplainComments(string(data))
+		if content == "" {
+			continue
+		}
+
+		summary, err := summarizeCached(ctx, content, cfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to summarize %s: %w", p.path, err)
+		}
+
+		title := blog.Title(content)
+		if title == "" {
+			title = filepath.Base(p.path)
+		}
+		topics = append(topics, title+": "+strings.Join(strings.Fields(summary), " "))
+	}
+	return topics, nil
+}
+
+// summarizeCached returns the cached summary for a post, generating and
+// caching it on a miss
+func summarizeCached(ctx context.Context, content string, cfg config) (string, error) {
+	key := cache.HashText(content)
+	if cfg.cache != nil {
+		summary, ok, err := cfg.cache.Get("summaries", key)
+		if err != nil {
+			return "", err
+		}
+		if ok {
+			return summary, nil
+		}
+	}
+
+	if !cfg.budget.allowsCall() {
+		return "", errBudgetExhausted
+	}
+	summary, err := blog.SummarizePost(ctx, content, cfg.Blog.Usage)
+	if err != nil {
+		return "", err
+	}
+
+	if cfg.cache != nil {
+		if err := cfg.cache.Put("summaries", key, summary); err != nil {
+			return "", err
+		}
+	}
+	return summary, nil
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/chezu/video-journal/internal/blog"
+)
+
+// titleNumberPlaceholder in --title-prefix or --title-suffix is replaced by
+// the next number from the title counter file
+const titleNumberPlaceholder = "{n}"
+
+// DefaultTitleCounter is the default counter file for titleNumberPlaceholder
+const DefaultTitleCounter = ".title-counter"
+
+// titleCounterState is the counter file's content: the last number used and
+// the number given to each output, so regenerating a post keeps its number
+type titleCounterState struct {
+	Last  int            `json:"last"`
+	Posts map[string]int `json:"posts"` // Absolute output path to number
+}
+
+// titleCounter hands out {n} numbers in input order. Each item has a
+// sequence number and takes its turn only after every earlier item has
+// finished, so a slow earlier video still gets the lower number. A number is
+// only recorded once the post is written, so failed items leave no gaps.
+type titleCounter struct {
+	path string
+	mu   sync.Mutex
+	cond *sync.Cond
+	turn int          // Sequence number of the item allowed to number next
+	done map[int]bool // Finished items waiting for earlier ones
+}
+
+// newTitleCounter returns a counter backed by the file at path
+func newTitleCounter(path string) *titleCounter {
+	c := &titleCounter{path: path, done: map[int]bool{}}
+	c.cond = sync.NewCond(&c.mu)
+	return c
+}
+
+// take waits for item seq's turn and returns the number for output: its
+// recorded number if it has one, otherwise the next unused one. The commit
+// function records the number and must be called only after the post is
+// written; the turn passes on when finish is called.
+func (c *titleCounter) take(seq int, output string) (int, func() error, error) {
+	c.mu.Lock()
+	for c.turn < seq {
+		c.cond.Wait()
+	}
+	c.mu.Unlock()
+
+	abs, err := filepath.Abs(output)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to resolve output path: %w", err)
+	}
+	state, err := c.load()
+	if err != nil {
+		return 0, nil, err
+	}
+	if n, ok := state.Posts[abs]; ok {
+		return n, func() error { return nil }, nil
+	}
+
+	n := state.Last + 1
+	return n, func() error {
+		state.Last = n
+		state.Posts[abs] = n
+		return c.save(state)
+	}, nil
+}
+
+// finish marks item seq as done, passing the turn on to the next items
+func (c *titleCounter) finish(seq int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.done[seq] = true
+	for c.done[c.turn] {
+		delete(c.done, c.turn)
+		c.turn++
+	}
+	c.cond.Broadcast()
+}
+
+// load reads the counter file. A missing file starts the series at 1, and
+// a file holding just a number (the last one used) is accepted so the count
+// can be set by hand.
+func (c *titleCounter) load() (titleCounterState, error) {
+	state := titleCounterState{Posts: map[string]int{}}
+	data, err := os.ReadFile(c.path)
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return state, fmt.Errorf("failed to read title counter: %w", err)
+	}
+	if last, err := strconv.Atoi(strings.TrimSpace(string(data))); err == nil && last >= 0 {
+		state.Last = last
+		return state, nil
+	}
+	if err := json.Unmarshal(data, &state); err != nil || state.Last < 0 {
+		return state, fmt.Errorf("title counter file %s must hold a non-negative number or a saved counter", c.path)
+	}
+	if state.Posts == nil {
+		state.Posts = map[string]int{}
+	}
+	return state, nil
+}
+
+// save writes the counter file via a temp file and rename so a crash can't
+// leave it empty
+func (c *titleCounter) save(state titleCounterState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode title counter: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), filepath.Base(c.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to update title counter: %w", err)
+	}
+	_, err = tmp.Write(append(data, '\n'))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), c.path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to update title counter: %w", err)
+	}
+	return nil
+}
+
+// applyTitleAffixes adds the configured prefix and suffix to the post's
+// title. It returns a function to call once the post is written, which
+// records the {n} number used, if any. A number is only taken when the post
+// has a title to put it in.
+func applyTitleAffixes(post string, cfg config) (string, func() error, error) {
+	commit := func() error { return nil }
+	if cfg.titlePrefix == "" && cfg.titleSuffix == "" {
+		return post, commit, nil
+	}
+	if _, ok := blog.AddTitleAffixes(post, "", ""); !ok {
+		fmt.Fprintf(os.Stderr, "Warning: post has no title; --title-prefix and --title-suffix not applied\n")
+		return post, commit, nil
+	}
+
+	prefix, suffix := cfg.titlePrefix, cfg.titleSuffix
+	if cfg.titleNumbers != nil {
+		n, take, err := cfg.titleNumbers.take(cfg.titleSeq, cfg.outputPath)
+		if err != nil {
+			return "", nil, err
+		}
+		commit = take
+		prefix = strings.ReplaceAll(prefix, titleNumberPlaceholder, strconv.Itoa(n))
+		suffix = strings.ReplaceAll(suffix, titleNumberPlaceholder, strconv.Itoa(n))
+	}
+	post, _ = blog.AddTitleAffixes(post, prefix, suffix)
+	fmt.Printf("Title: %s\n", blog.Title(post))
+	return post, commit, nil
+}
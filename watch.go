@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chezu/video-journal/internal/cache"
+)
+
+// DefaultWatchInterval is how often watch mode polls the directory
+const DefaultWatchInterval = 10 * time.Second
+
+// watchedFile is a candidate video seen while polling
+type watchedFile struct {
+	size    int64
+	modTime time.Time
+}
+
+// runWatch polls dir for new videos and runs the pipeline on each one once
+// it has stopped growing, with up to workers videos in progress at once.
+// Handled files are remembered in the cache (keyed by path, size, and
+// modification time) so restarts don't reprocess them; a file that changes
+// afterwards is treated as new. Runs until ctx is canceled.
+func runWatch(ctx context.Context, dir string, interval time.Duration, prepare func(string) (config, error), base config, workers int) error {
+	fmt.Printf("Watching %s for new videos (every %v, Ctrl+C to stop)\n", dir, interval)
+	if base.cache == nil {
+		fmt.Fprintf(os.Stderr, "Warning: without the cache, handled videos are only remembered until watch exits\n")
+	}
+
+	pending := map[string]watchedFile{} // Files seen growing or not yet stable
+	var (
+		mu         sync.Mutex // Guards handled and running
+		handled    = map[string]bool{}
+		running    = map[string]bool{} // Paths being processed
+		dispatched int                 // Videos started, for numbering titles in order
+		wg         sync.WaitGroup
+		slots      = make(chan struct{}, max(workers, 1))
+		finished   = func(path, key string, err error) {
+			switch {
+			case errors.Is(err, errBudgetExhausted):
+				fmt.Printf("Skipped %s: %v\n", path, err)
+				return // Retry if the budget allows later, e.g. after a restart
+			case errors.Is(err, context.Canceled):
+				return
+			case errors.Is(err, errUnchanged):
+				fmt.Printf("Skipped %s: %v\n", path, err)
+			case err != nil:
+				// Not retried automatically: rerun the file by hand after fixing the cause
+				fmt.Fprintf(os.Stderr, "Error: %s: %v\n", path, err)
+			}
+			handled[key] = true
+			markWatchHandled(base.cache, key, path)
+		}
+	)
+	defer wg.Wait()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return fmt.Errorf("failed to read watched directory: %w", err)
+		}
+
+		for _, entry := range entries {
+			if ctx.Err() != nil {
+				break
+			}
+			name := entry.Name()
+			if entry.IsDir() || !isWatchCandidate(name) {
+				continue
+			}
+			path := filepath.Join(dir, name)
+			info, err := entry.Info()
+			if err != nil {
+				continue // Removed since listing
+			}
+			current := watchedFile{size: info.Size(), modTime: info.ModTime()}
+			key := watchKey(path, current)
+			mu.Lock()
+			skip := handled[key] || running[path]
+			mu.Unlock()
+			if skip || watchHandled(base.cache, key) {
+				delete(pending, path)
+				continue
+			}
+
+			// Wait for the size and modification time to hold still for a
+			// full interval so files still being copied are left alone
+			if previous, ok := pending[path]; !ok || previous != current || current.size == 0 {
+				pending[path] = current
+				continue
+			}
+			select {
+			case slots <- struct{}{}:
+			default:
+				continue // All workers busy; still stable next poll
+			}
+			delete(pending, path)
+
+			mu.Lock()
+			running[path] = true
+			mu.Unlock()
+			seq := dispatched
+			dispatched++
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer func() { <-slots }()
+				fmt.Printf("\n=== %s ===\n", path)
+				err := processWatched(ctx, path, seq, prepare, base.budget)
+				mu.Lock()
+				defer mu.Unlock()
+				delete(running, path)
+				finished(path, key, err)
+			}()
+		}
+
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			fmt.Println("\nStopped watching")
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// processWatched runs the pipeline on one watched video. seq orders the
+// video among those dispatched, for title numbering.
+func processWatched(ctx context.Context, path string, seq int, prepare func(string) (config, error), b *budget) error {
+	cfg, err := prepare(path)
+	if cfg.titleNumbers != nil {
+		defer cfg.titleNumbers.finish(seq)
+		cfg.titleSeq = seq
+	}
+	if err != nil {
+		return err
+	}
+	if !cfg.json && !b.allowsCall() {
+		return errBudgetExhausted
+	}
+	return runItem(ctx, path, cfg)
+}
+
+// isWatchCandidate reports whether a file name looks like a finished video.
+// Hidden files and editor or download temp names are skipped; partial
+// downloads usually carry a non-video extension (.part, .crdownload) and are
+// skipped by the extension check.
+func isWatchCandidate(name string) bool {
+	if strings.HasPrefix(name, ".") || strings.HasPrefix(name, "~") || strings.HasPrefix(name, "#") {
+		return false
+	}
+	return validVideoExtensions[strings.ToLower(filepath.Ext(name))]
+}
+
+// watchKey identifies a specific version of a watched file
+func watchKey(path string, f watchedFile) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		abs = path
+	}
+	return cache.HashText(fmt.Sprintf("%s\x00%d\x00%d", abs, f.size, f.modTime.UnixNano()))
+}
+
+// watchHandled reports whether the cache records the file version as handled
+func watchHandled(c *cache.Cache, key string) bool {
+	if c == nil {
+		return false
+	}
+	_, ok, err := c.Get("watched", key)
+	return err == nil && ok
+}
+
+// markWatchHandled records a file version as handled. Failures only warn
+// since the in-memory record still prevents reprocessing this session.
+func markWatchHandled(c *cache.Cache, key, path string) {
+	if c == nil {
+		return
+	}
+	if err := c.Put("watched", key, path); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+}